@@ -0,0 +1,157 @@
+package workers
+
+import (
+	"context"
+	"iter"
+	"slices"
+	"time"
+)
+
+// BatchJobList is a job list whose function receives the items in batches.
+// A batch is dispatched once it has size items or its oldest item has waited
+// for the max wait, and it occupies one worker slot. Stats counts items, those of
+// a batch being started, completed or failed together; a dead-letter sink set with
+// WithDeadLetter receives failed batches as DeadLetter[[]T].
+type BatchJobList[T any] struct {
+	*jobList[[]T]
+	q *batchQueue[T]
+}
+
+// NewBatchJobList creates a new BatchJobList with the given Runner, batch size,
+// max wait and job function. If wait is not positive, the items queued when a
+// worker is ready are dispatched at once, up to size. The options apply to the
+// dispatch of the batches as for JobList, and WithCapacity bounds the pending items.
+func NewBatchJobList[T any](r Runner, size int, wait time.Duration, f func(context.Context, []T) error, opts ...Option) *BatchJobList[T] {
+	q := &batchQueue[T]{size: max(size, 1), wait: wait}
+	l := &BatchJobList[T]{newJobList(q, r, f, opts), q}
+	l.deadLetter = deadLetter(opts, func(v []T) []T { return v }, false)
+	l.count = func(v []T) int64 { return int64(len(v)) }
+	q.timer = time.AfterFunc(time.Hour, func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.signal()
+	})
+	q.timer.Stop()
+	return l
+}
+
+// Push adds an item to the current batch and signals the worker pool.
+func (l *BatchJobList[T]) Push(v T) error {
+	return l.PushContext(context.Background(), v)
+}
+
+// PushContext is like Push, but gives up when ctx is done while blocked by a full job list.
+func (l *BatchJobList[T]) PushContext(ctx context.Context, v T) error {
+	return l.push(ctx, func() { l.q.push(v) })
+}
+
+// Pending returns an iterator over a snapshot of the pending items, in push order.
+func (l *BatchJobList[T]) Pending() iter.Seq[T] {
+	l.mu.Lock()
+	items := l.q.values()
+	l.mu.Unlock()
+	return slices.Values(items)
+}
+
+// Shutdown dispatches the pending items without waiting for full batches, then
+// behaves as for JobList, returning the items still pending if ctx is done first.
+func (l *BatchJobList[T]) Shutdown(ctx context.Context) ([]T, error) {
+	l.mu.Lock()
+	l.q.flush = true
+	l.signal()
+	l.mu.Unlock()
+	batches, err := l.jobList.Shutdown(ctx)
+	return slices.Concat(batches...), err
+}
+
+type batchItem[T any] struct {
+	v  T
+	at time.Time
+}
+
+// batchQueue holds the pending items of a BatchJobList, popped as batches.
+// Its timer signals the dispatcher when the oldest item has waited long enough.
+type batchQueue[T any] struct {
+	size  int
+	wait  time.Duration
+	flush bool // dispatch partial batches at once
+	timer *time.Timer
+	buf   []batchItem[T]
+}
+
+func (q *batchQueue[T]) push(v T) {
+	q.buf = append(q.buf, batchItem[T]{v, time.Now()})
+	if len(q.buf) == 1 {
+		q.schedule()
+	}
+}
+
+// schedule sets the timer for the oldest item.
+func (q *batchQueue[T]) schedule() {
+	if len(q.buf) == 0 || q.wait <= 0 {
+		q.timer.Stop()
+		return
+	}
+	q.timer.Reset(time.Until(q.buf[0].at.Add(q.wait)))
+}
+
+func (q *batchQueue[T]) Len() int { return len(q.buf) }
+
+// ready returns the number of batches which can be dispatched now.
+func (q *batchQueue[T]) ready() int {
+	n := len(q.buf)
+	if full := n / q.size; full > 0 {
+		return full
+	}
+	if n > 0 && (q.flush || q.wait <= 0 || time.Since(q.buf[0].at) >= q.wait) {
+		return 1
+	}
+	return 0
+}
+
+// pop returns the next batch if it is ready, since the dispatcher may have
+// sent a job for it before the items still pending became ready.
+func (q *batchQueue[T]) pop() ([]T, bool) {
+	if q.ready() == 0 {
+		return nil, false
+	}
+	n := min(len(q.buf), q.size)
+	batch := make([]T, n)
+	for i, item := range q.buf[:n] {
+		batch[i] = item.v
+	}
+	clear(q.buf[:n])
+	q.buf = q.buf[n:]
+	q.schedule()
+	return batch, true
+}
+
+func (q *batchQueue[T]) dropOldest() ([]T, bool) {
+	if len(q.buf) == 0 {
+		return nil, false
+	}
+	v := q.buf[0].v
+	clear(q.buf[:1])
+	q.buf = q.buf[1:]
+	q.schedule()
+	return []T{v}, true
+}
+
+// items returns the pending items as batches of size.
+func (q *batchQueue[T]) items() [][]T {
+	return slices.Collect(slices.Chunk(q.values(), q.size))
+}
+
+// values returns the pending items.
+func (q *batchQueue[T]) values() []T {
+	s := make([]T, len(q.buf))
+	for i, item := range q.buf {
+		s[i] = item.v
+	}
+	return s
+}
+
+func (q *batchQueue[T]) clear() {
+	q.buf = nil
+	q.timer.Stop()
+}
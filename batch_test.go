@@ -0,0 +1,46 @@
+package workers
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBatchJobList(t *testing.T) {
+	var mu sync.Mutex
+	var res [][]int
+	list := NewBatchJobList(Workers(1), 3, 50*time.Millisecond, func(_ context.Context, batch []int) error {
+		mu.Lock()
+		res = append(res, batch)
+		mu.Unlock()
+		return nil
+	})
+	list.Start(t.Context())
+	for i := range 7 {
+		list.Push(i)
+	}
+	time.Sleep(10 * time.Millisecond)
+	mu.Lock()
+	if expect := [][]int{{0, 1, 2}, {3, 4, 5}}; !reflect.DeepEqual(expect, res) {
+		t.Errorf("expected %v; got %v", expect, res)
+	}
+	mu.Unlock()
+	if expect := []int{6}; !reflect.DeepEqual(expect, slices.Collect(list.Pending())) {
+		t.Errorf("expected %v; got %v", expect, slices.Collect(list.Pending()))
+	}
+	time.Sleep(60 * time.Millisecond)
+	list.Push(7)
+	list.Push(8)
+	if _, err := list.Shutdown(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if expect := [][]int{{0, 1, 2}, {3, 4, 5}, {6}, {7, 8}}; !reflect.DeepEqual(expect, res) {
+		t.Errorf("expected %v; got %v", expect, res)
+	}
+	if expect := (Stats{Pushed: 9, Started: 9, Completed: 9}); list.Stats() != expect {
+		t.Errorf("expected %+v; got %+v", expect, list.Stats())
+	}
+}
@@ -0,0 +1,153 @@
+package workers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule reports when a scheduled job runs next.
+type Schedule interface {
+	// Next returns the first activation time after t.
+	Next(t time.Time) time.Time
+}
+
+// Every returns a Schedule activating every d. It panics if d is not positive.
+func Every(d time.Duration) Schedule {
+	if d <= 0 {
+		panic("workers: non-positive interval for Every")
+	}
+	return every(d)
+}
+
+type every time.Duration
+
+func (d every) Next(t time.Time) time.Time { return t.Add(time.Duration(d)) }
+
+// cronSchedule holds the allowed values of each field as bit sets.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// anyDay is set if the day of month or the day of week is *, in which case
+	// both must match. Otherwise a day matches if either does, as in cron.
+	anyDay bool
+}
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseCron parses a standard cron expression with five fields: minute, hour,
+// day of month, month and day of week (0 or 7 is Sunday). A field is * or a
+// comma-separated list of values, ranges a-b and steps */n, a-b/n or a/n.
+// The descriptors @yearly, @annually, @monthly, @weekly, @daily, @midnight
+// and @hourly are accepted too. Times are evaluated in the location of the
+// time passed to Next.
+func ParseCron(spec string) (Schedule, error) {
+	if s, ok := cronDescriptors[spec]; ok {
+		spec = s
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q: expected 5 fields; got %d", spec, len(fields))
+	}
+	s := new(cronSchedule)
+	for i, f := range []struct {
+		bits     *uint64
+		min, max int
+	}{
+		{&s.minute, 0, 59},
+		{&s.hour, 0, 23},
+		{&s.dom, 1, 31},
+		{&s.month, 1, 12},
+		{&s.dow, 0, 7},
+	} {
+		bits, err := parseCronField(fields[i], f.min, f.max)
+		if err != nil {
+			return nil, fmt.Errorf("cron expression %q: %v", spec, err)
+		}
+		*f.bits = bits
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.anyDay = fields[2] == "*" || fields[4] == "*"
+	return s, nil
+}
+
+func parseCronField(field string, min, max int) (bits uint64, err error) {
+	for part := range strings.SplitSeq(field, ",") {
+		rng, step, hasStep := strings.Cut(part, "/")
+		lo, hi := min, max
+		if rng != "*" {
+			a, b, isRange := strings.Cut(rng, "-")
+			if lo, err = parseCronValue(a, min, max); err != nil {
+				return
+			}
+			if isRange {
+				if hi, err = parseCronValue(b, min, max); err != nil {
+					return
+				}
+				if hi < lo {
+					return 0, fmt.Errorf("invalid range %q", rng)
+				}
+			} else if !hasStep {
+				hi = lo
+			}
+		}
+		n := 1
+		if hasStep {
+			if n, err = strconv.Atoi(step); err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q", step)
+			}
+		}
+		for i := lo; i <= hi; i += n {
+			bits |= 1 << i
+		}
+	}
+	return
+}
+
+func parseCronValue(s string, min, max int) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < min || n > max {
+		return 0, fmt.Errorf("invalid value %q, expected %d-%d", s, min, max)
+	}
+	return n, nil
+}
+
+// Next returns the first minute after t matching the schedule, or the zero time
+// if there is none within five years.
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	for limit := t.AddDate(5, 0, 0); t.Before(limit); {
+		switch {
+		case s.month&(1<<t.Month()) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.matchDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case s.hour&(1<<t.Hour()) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case s.minute&(1<<t.Minute()) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (s *cronSchedule) matchDay(t time.Time) bool {
+	dom := s.dom&(1<<t.Day()) != 0
+	dow := s.dow&(1<<t.Weekday()) != 0
+	if s.anyDay {
+		return dom && dow
+	}
+	return dom || dow
+}
@@ -0,0 +1,74 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+)
+
+// DeadLetter is an item of a job list which still failed after its retries.
+type DeadLetter[T any] struct {
+	// Item is the failed item.
+	Item T
+	// Err is the error of the last attempt.
+	Err error
+	// Attempts is the number of attempts made.
+	Attempts int
+}
+
+// WithDeadLetter routes the items of a job list of T failing after their retries,
+// see WithRetry, to f. Without a sink, the failures of the job lists returning no
+// Handle are logged, except panics reported by the panic policy. f is called by the
+// worker which ran the item. It panics when the job list is created if its items
+// are not of type T.
+func WithDeadLetter[T any](f func(DeadLetter[T])) Option {
+	return withDeadLetter(func(_ context.Context, d DeadLetter[T]) { f(d) })
+}
+
+// WithDeadLetterChan is like WithDeadLetter, but sends the failed items to c.
+// The worker blocks until c receives the item or the job list's context is done.
+func WithDeadLetterChan[T any](c chan<- DeadLetter[T]) Option {
+	return withDeadLetter(func(ctx context.Context, d DeadLetter[T]) {
+		select {
+		case c <- d:
+		case <-ctx.Done():
+		}
+	})
+}
+
+// WithDeadLetterList is like WithDeadLetter, but pushes the failed items to the back of l.
+// Items which cannot be pushed, e.g. because l is closed, are logged.
+func WithDeadLetterList[T any](l *JobList[DeadLetter[T]]) Option {
+	return withDeadLetter(func(ctx context.Context, d DeadLetter[T]) {
+		if _, err := l.PushBackContext(ctx, d); err != nil {
+			log.Printf("dead letter lost: job failed after %d attempts: %v: %v", d.Attempts, d.Err, err)
+		}
+	})
+}
+
+func withDeadLetter[T any](f func(context.Context, DeadLetter[T])) Option {
+	return func(c *config) { c.deadLetter = f }
+}
+
+// deadLetter returns the dead-letter sink of opts for the queued values of type E,
+// holding items of type T returned by value. Unless the failures are reported
+// otherwise, the default sink logs them, except the panics already handled by
+// panicError.
+func deadLetter[E, T any](opts []Option, value func(E) T, reported bool) func(context.Context, E, error, int) {
+	sink := newConfig(opts).deadLetter
+	if sink == nil {
+		return func(_ context.Context, _ E, err error, _ int) {
+			if _, ok := err.(*PanicError); !ok && !reported {
+				log.Printf("job failed: %v", err)
+			}
+		}
+	}
+	f, ok := sink.(func(context.Context, DeadLetter[T]))
+	if !ok {
+		panic(fmt.Sprintf("workers: dead letter sink %T used with a job list of %v", sink, reflect.TypeFor[T]()))
+	}
+	return func(ctx context.Context, v E, err error, attempts int) {
+		f(ctx, DeadLetter[T]{Item: value(v), Err: err, Attempts: attempts})
+	}
+}
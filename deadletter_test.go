@@ -0,0 +1,98 @@
+package workers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestDeadLetter(t *testing.T) {
+	errOdd := errors.New("odd")
+	f := func(_ context.Context, i int) error {
+		if i%2 == 1 {
+			return errOdd
+		}
+		return nil
+	}
+	retry := WithRetry(RetryPolicy{MaxAttempts: 3})
+
+	var got []DeadLetter[int]
+	list := NewErrJobList(1, f, retry, WithDeadLetter(func(d DeadLetter[int]) { got = append(got, d) }))
+	list.Start(t.Context())
+	for i := range 4 {
+		list.PushBack(i)
+	}
+	if _, err := list.Shutdown(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 dead letters; got %d", len(got))
+	}
+	for i, d := range got {
+		if expect := (DeadLetter[int]{Item: 2*i + 1, Err: errOdd, Attempts: 3}); d != expect {
+			t.Errorf("expected %v; got %v", expect, d)
+		}
+	}
+
+	c := make(chan DeadLetter[int], 1)
+	list = NewErrJobList(1, f, WithDeadLetterChan(c))
+	list.Start(t.Context())
+	list.PushBack(1)
+	if d := <-c; d.Item != 1 || d.Attempts != 1 {
+		t.Errorf("expected item 1 after 1 attempt; got %v", d)
+	}
+	list.Close()
+
+	dead := NewJobList(1, func(d DeadLetter[int]) { c <- d })
+	dead.Start(t.Context())
+	priority := NewPriorityJobList(Workers(1), f, retry, WithDeadLetterList(dead))
+	priority.Start(t.Context())
+	priority.PushWithPriority(3, 0)
+	if d := <-c; d.Item != 3 || d.Attempts != 3 {
+		t.Errorf("expected item 3 after 3 attempts; got %v", d)
+	}
+	priority.Close()
+	dead.Close()
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for mismatched dead letter sink")
+		}
+	}()
+	NewJobList(1, func(string) {}, WithDeadLetter(func(DeadLetter[int]) {}))
+}
+
+func TestDeadLetterDefault(t *testing.T) {
+	var buf bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&buf)
+	f := func(_ context.Context, i int) error {
+		if i == 0 {
+			panic("boom")
+		}
+		return errors.New("failed")
+	}
+
+	list := NewErrJobList(1, f)
+	list.Start(t.Context())
+	list.PushBack(1)
+	list.Shutdown(t.Context())
+	if buf.Len() != 0 {
+		t.Errorf("expected failure reported by handle not to be logged; got %q", buf.String())
+	}
+
+	priority := NewPriorityJobList(Workers(1), f)
+	priority.Start(t.Context())
+	priority.PushWithPriority(0, 0)
+	priority.PushWithPriority(1, 0)
+	priority.Shutdown(t.Context())
+	if n := strings.Count(buf.String(), "panic: boom"); n != 1 {
+		t.Errorf("expected panic logged once; got %d times", n)
+	}
+	if n := strings.Count(buf.String(), "job failed: failed"); n != 1 {
+		t.Errorf("expected failure logged once; got %d times", n)
+	}
+}
@@ -0,0 +1,108 @@
+package workers
+
+import (
+	"cmp"
+	"container/heap"
+	"context"
+	"time"
+)
+
+// PushAt adds a job to the job list which is pushed to its back once t is reached.
+// Until then it counts as pending and can be canceled with the returned Handle.
+func (l *JobList[T]) PushAt(v T, t time.Time) (*Handle[T], error) {
+	return l.PushAtContext(context.Background(), v, t)
+}
+
+// PushAtContext is like PushAt, but gives up when ctx is done while blocked by a full job list.
+func (l *JobList[T]) PushAtContext(ctx context.Context, v T, t time.Time) (*Handle[T], error) {
+	return l.pushHandle(ctx, v, func(h *Handle[T]) { l.q.pushAt(h, t) })
+}
+
+// PushAfter adds a job to the job list which is pushed to its back after d.
+func (l *JobList[T]) PushAfter(v T, d time.Duration) (*Handle[T], error) {
+	return l.PushAt(v, time.Now().Add(d))
+}
+
+// Delayed returns the number of pending items which are not due yet.
+func (l *JobList[T]) Delayed() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.q.delayed)
+}
+
+// promote moves the due items to the back of the queue and signals the dispatcher.
+func (l *JobList[T]) promote() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.q.promote(time.Now()) > 0 {
+		l.signal()
+	}
+}
+
+// pushAt adds h to the back of the queue at t, or now if t has been reached.
+func (q *listQueue[T]) pushAt(h *Handle[T], t time.Time) {
+	if !t.After(time.Now()) {
+		h.e = q.PushBack(h)
+		return
+	}
+	q.seq++
+	h.at, h.seq = t, q.seq
+	heap.Push(&q.delayed, h)
+	if h.index == 0 {
+		q.schedule()
+	}
+}
+
+// promote moves the items due at now to the back of the queue, returning their number.
+func (q *listQueue[T]) promote(now time.Time) (n int) {
+	for len(q.delayed) > 0 && !q.delayed[0].at.After(now) {
+		h := heap.Pop(&q.delayed).(*Handle[T])
+		h.e = q.PushBack(h)
+		n++
+	}
+	q.schedule()
+	return
+}
+
+// schedule sets the timer for the delayed item due first.
+func (q *listQueue[T]) schedule() {
+	if len(q.delayed) == 0 {
+		q.timer.Stop()
+		return
+	}
+	q.timer.Reset(time.Until(q.delayed[0].at))
+}
+
+func (h *Handle[T]) compare(o *Handle[T]) int {
+	if c := h.at.Compare(o.at); c != 0 {
+		return c
+	}
+	return cmp.Compare(h.seq, o.seq)
+}
+
+// delayQueue is a min-heap of delayed handles by due time, then push order.
+type delayQueue[T any] []*Handle[T]
+
+func (q delayQueue[T]) Len() int           { return len(q) }
+func (q delayQueue[T]) Less(i, j int) bool { return q[i].compare(q[j]) < 0 }
+func (q delayQueue[T]) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *delayQueue[T]) Push(x any) {
+	h := x.(*Handle[T])
+	h.index = len(*q)
+	*q = append(*q, h)
+}
+
+func (q *delayQueue[T]) Pop() any {
+	old := *q
+	n := len(old)
+	h := old[n-1]
+	old[n-1] = nil
+	h.index = -1
+	*q = old[:n-1]
+	return h
+}
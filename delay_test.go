@@ -0,0 +1,83 @@
+package workers
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestJobListDelay(t *testing.T) {
+	var mu sync.Mutex
+	var res []int
+	list := NewJobList(1, func(i int) {
+		mu.Lock()
+		res = append(res, i)
+		mu.Unlock()
+	})
+	list.Start(t.Context())
+	start := time.Now()
+	list.PushAfter(3, 150*time.Millisecond)
+	list.PushAt(1, start.Add(50*time.Millisecond))
+	h, _ := list.PushAt(4, start.Add(100*time.Millisecond))
+	list.PushAt(2, start.Add(100*time.Millisecond))
+	if expect := []int{1, 4, 2, 3}; !reflect.DeepEqual(expect, slices.Collect(list.Pending())) {
+		t.Errorf("expected %v; got %v", expect, slices.Collect(list.Pending()))
+	}
+	if n := list.Delayed(); n != 4 {
+		t.Errorf("expected 4 delayed; got %d", n)
+	}
+	if !h.Cancel() {
+		t.Error("expected delayed item to be canceled")
+	}
+	list.PushAt(0, start.Add(-time.Second))
+	if _, err := list.Shutdown(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d < 150*time.Millisecond {
+		t.Errorf("expected shutdown to wait for delayed items; got %s", d)
+	}
+	if expect := []int{0, 1, 2, 3}; !reflect.DeepEqual(expect, res) {
+		t.Errorf("expected %v; got %v", expect, res)
+	}
+
+	list = NewJobList(1, func(int) {})
+	list.Start(t.Context())
+	h, _ = list.PushAfter(1, time.Hour)
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+	items, err := list.Shutdown(ctx)
+	if err != context.DeadlineExceeded {
+		t.Errorf("expected %v; got %v", context.DeadlineExceeded, err)
+	}
+	if expect := []int{1}; !reflect.DeepEqual(expect, items) {
+		t.Errorf("expected %v; got %v", expect, items)
+	}
+	if err := h.Err(); err != ErrDropped {
+		t.Errorf("expected %v; got %v", ErrDropped, err)
+	}
+}
+
+func TestJobListDelayDropOldest(t *testing.T) {
+	list := NewJobList(1, func(int) {}, WithCapacity(2, OverflowDropOldest))
+	h1, _ := list.PushAfter(1, time.Hour)
+	h2, _ := list.PushAfter(2, 3*time.Hour)
+	if _, err := list.PushAfter(3, 2*time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := h1.Err(); err != ErrDropped {
+		t.Errorf("expected %v; got %v", ErrDropped, err)
+	}
+	if _, err := list.PushAfter(4, 4*time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if expect := []int{2, 4}; !reflect.DeepEqual(expect, slices.Collect(list.Pending())) {
+		t.Errorf("expected %v; got %v", expect, slices.Collect(list.Pending()))
+	}
+	if !h2.Cancel() {
+		t.Error("expected delayed item to be canceled")
+	}
+	list.Close()
+}
@@ -1,6 +1,10 @@
 package workers
 
-import "sync/atomic"
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+)
 
 // Job interface defines a method to get the next job function.
 // The returned bool indicates whether there might be more jobs.
@@ -11,6 +15,46 @@ type Job interface {
 	Next() (func(), bool)
 }
 
+// ErrJob interface is like Job, but the job function returns an error.
+// Errors returned by job functions are aggregated by Workers.RunErr.
+type ErrJob interface {
+	Next() (func() error, bool)
+}
+
+// ContextJob interface is like ErrJob, but the job function receives a context
+// derived from the one passed to Workers.RunContext, which is cancelled when
+// the run is cancelled.
+type ContextJob interface {
+	Next() (func(context.Context) error, bool)
+}
+
+// WeightedJob interface is like ContextJob, but Next also returns the weight of the job,
+// i.e. how much of the worker limit the job consumes while it is running.
+// Weights are clamped to [1, size of the workers]. A Pool only raises weights to 1:
+// a weight above its size, e.g. after it is shrunk, is admitted once the pool is idle.
+type WeightedJob interface {
+	Next() (func(context.Context) error, int64, bool)
+}
+
+// JobError records the error returned by a job function together with
+// the index, key or value of the item being processed.
+type JobError struct {
+	Key any
+	Err error
+}
+
+func (e *JobError) Error() string { return fmt.Sprintf("job %v: %v", e.Key, e.Err) }
+
+// Unwrap returns the underlying error.
+func (e *JobError) Unwrap() error { return e.Err }
+
+func jobError(key any, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &JobError{key, err}
+}
+
 // FuncJob type defines a single function job.
 type FuncJob func()
 
@@ -22,18 +66,67 @@ func (job FuncJob) Next() (func(), bool) {
 	return job, true
 }
 
+// ErrFuncJob type defines a single error-returning function job.
+type ErrFuncJob func() error
+
+// Next always returns itself with (job, true), unless job is nil.
+func (job ErrFuncJob) Next() (func() error, bool) {
+	if job == nil {
+		return nil, false
+	}
+	return job, true
+}
+
+// ContextFuncJob type defines a single context-aware function job.
+type ContextFuncJob func(context.Context) error
+
+// Next always returns itself with (job, true), unless job is nil.
+func (job ContextFuncJob) Next() (func(context.Context) error, bool) {
+	if job == nil {
+		return nil, false
+	}
+	return job, true
+}
+
 // SliceJob creates a Job that iterates over a slice and applies a function to each element.
 func SliceJob[T any](s []T, f func(int, T)) Job {
-	return &sliceJob[T]{s: s, f: f}
+	return &sliceJob[T]{sliceIter: sliceIter[T]{s: s}, f: f}
+}
+
+// SliceErrJob creates an ErrJob that iterates over a slice and applies a function to each element.
+// Errors are reported as *JobError with the element index as Key.
+func SliceErrJob[T any](s []T, f func(int, T) error) ErrJob {
+	return &sliceErrJob[T]{sliceIter: sliceIter[T]{s: s}, f: f}
+}
+
+// SliceContextJob creates a ContextJob that iterates over a slice and applies a function to each element.
+// Errors are reported as *JobError with the element index as Key.
+func SliceContextJob[T any](s []T, f func(context.Context, int, T) error) ContextJob {
+	return &sliceContextJob[T]{sliceIter: sliceIter[T]{s: s}, f: f}
+}
+
+// SliceWeightedJob creates a WeightedJob that iterates over a slice and applies a function to each element,
+// with the weight of each element given by weight.
+// Errors are reported as *JobError with the element index as Key.
+func SliceWeightedJob[T any](s []T, weight func(T) int64, f func(context.Context, int, T) error) WeightedJob {
+	return &sliceWeightedJob[T]{sliceContextJob: sliceContextJob[T]{sliceIter: sliceIter[T]{s: s}, f: f}, weight: weight}
 }
 
 // MapJob creates a Job that iterates over a map and applies a function to each key-value pair.
 func MapJob[M ~map[K]V, K comparable, V any](m M, f func(K, V)) Job {
-	r := make([]K, 0, len(m))
-	for k := range m {
-		r = append(r, k)
-	}
-	return &mapJob[M, K, V]{m: m, keys: r, f: f}
+	return &mapJob[M, K, V]{mapIter: newMapIter(m), f: f}
+}
+
+// MapErrJob creates an ErrJob that iterates over a map and applies a function to each key-value pair.
+// Errors are reported as *JobError with the map key as Key.
+func MapErrJob[M ~map[K]V, K comparable, V any](m M, f func(K, V) error) ErrJob {
+	return &mapErrJob[M, K, V]{mapIter: newMapIter(m), f: f}
+}
+
+// MapContextJob creates a ContextJob that iterates over a map and applies a function to each key-value pair.
+// Errors are reported as *JobError with the map key as Key.
+func MapContextJob[M ~map[K]V, K comparable, V any](m M, f func(context.Context, K, V) error) ContextJob {
+	return &mapContextJob[M, K, V]{mapIter: newMapIter(m), f: f}
 }
 
 // Integer interface defines a set of integer types.
@@ -44,9 +137,19 @@ type Integer interface {
 
 // RangeJob creates a Job that iterates over a range of integers and applies a function to each value.
 func RangeJob[T Integer](start, end T, f func(T)) Job {
-	n := new(atomic.Int64)
-	n.Store(int64(start))
-	return &rangeJob[T]{start: start, end: end, n: n, f: f}
+	return &rangeJob[T]{rangeIter: newRangeIter(start, end), f: f}
+}
+
+// RangeErrJob creates an ErrJob that iterates over a range of integers and applies a function to each value.
+// Errors are reported as *JobError with the value as Key.
+func RangeErrJob[T Integer](start, end T, f func(T) error) ErrJob {
+	return &rangeErrJob[T]{rangeIter: newRangeIter(start, end), f: f}
+}
+
+// RangeContextJob creates a ContextJob that iterates over a range of integers and applies a function to each value.
+// Errors are reported as *JobError with the value as Key.
+func RangeContextJob[T Integer](start, end T, f func(context.Context, T) error) ContextJob {
+	return &rangeContextJob[T]{rangeIter: newRangeIter(start, end), f: f}
 }
 
 var (
@@ -54,57 +157,239 @@ var (
 	_ Job = new(sliceJob[any])
 	_ Job = new(mapJob[map[string]any, string, any])
 	_ Job = new(rangeJob[int])
+
+	_ ErrJob = ErrFuncJob(nil)
+	_ ErrJob = new(sliceErrJob[any])
+	_ ErrJob = new(mapErrJob[map[string]any, string, any])
+	_ ErrJob = new(rangeErrJob[int])
+
+	_ ContextJob = ContextFuncJob(nil)
+	_ ContextJob = new(sliceContextJob[any])
+	_ ContextJob = new(mapContextJob[map[string]any, string, any])
+	_ ContextJob = new(rangeContextJob[int])
+
+	_ WeightedJob = new(sliceWeightedJob[any])
 )
 
+// jobNext adapts the Next method of a Job to run.
+func jobNext(job Job) nextFunc {
+	return func() (func(context.Context) error, int64, bool) {
+		f, next := job.Next()
+		if f == nil {
+			return nil, 0, next
+		}
+		return func(context.Context) error { f(); return nil }, 1, next
+	}
+}
+
+// errJobNext adapts the Next method of an ErrJob to run.
+func errJobNext(job ErrJob) nextFunc {
+	return func() (func(context.Context) error, int64, bool) {
+		f, next := job.Next()
+		if f == nil {
+			return nil, 0, next
+		}
+		return func(context.Context) error { return f() }, 1, next
+	}
+}
+
+// contextJobNext adapts the Next method of a ContextJob to run.
+func contextJobNext(job ContextJob) nextFunc {
+	return func() (func(context.Context) error, int64, bool) {
+		f, next := job.Next()
+		return f, 1, next
+	}
+}
+
 // --- SliceJob implementation ---
-type sliceJob[T any] struct {
+type sliceIter[T any] struct {
 	s     []T
 	index atomic.Int64
-	f     func(int, T)
+}
+
+func (it *sliceIter[T]) next() (n int, ok, more bool) {
+	n = int(it.index.Add(1)) - 1
+	if n > len(it.s)-1 {
+		return
+	}
+	return n, true, n < len(it.s)-1
+}
+
+type sliceJob[T any] struct {
+	sliceIter[T]
+	f func(int, T)
 }
 
 func (job *sliceJob[T]) Next() (func(), bool) {
-	n := int(job.index.Add(1)) - 1
-	if n > len(job.s)-1 {
+	n, ok, more := job.next()
+	if !ok {
+		return nil, false
+	}
+	return func() { job.f(n, job.s[n]) }, more
+}
+
+type sliceErrJob[T any] struct {
+	sliceIter[T]
+	f func(int, T) error
+}
+
+func (job *sliceErrJob[T]) Next() (func() error, bool) {
+	n, ok, more := job.next()
+	if !ok {
+		return nil, false
+	}
+	return func() error { return jobError(n, job.f(n, job.s[n])) }, more
+}
+
+type sliceContextJob[T any] struct {
+	sliceIter[T]
+	f func(context.Context, int, T) error
+}
+
+func (job *sliceContextJob[T]) Next() (func(context.Context) error, bool) {
+	n, ok, more := job.next()
+	if !ok {
 		return nil, false
 	}
-	return func() { job.f(n, job.s[n]) }, n < len(job.s)-1
+	return func(ctx context.Context) error { return jobError(n, job.f(ctx, n, job.s[n])) }, more
+}
+
+type sliceWeightedJob[T any] struct {
+	sliceContextJob[T]
+	weight func(T) int64
+}
+
+func (job *sliceWeightedJob[T]) Next() (func(context.Context) error, int64, bool) {
+	n, ok, more := job.next()
+	if !ok {
+		return nil, 0, false
+	}
+	return func(ctx context.Context) error { return jobError(n, job.f(ctx, n, job.s[n])) }, job.weight(job.s[n]), more
 }
 
 // --- MapJob implementation ---
-type mapJob[M ~map[K]V, K comparable, V any] struct {
+type mapIter[M ~map[K]V, K comparable, V any] struct {
 	m     M
 	keys  []K
 	index atomic.Int64
-	f     func(K, V)
+}
+
+func newMapIter[M ~map[K]V, K comparable, V any](m M) mapIter[M, K, V] {
+	r := make([]K, 0, len(m))
+	for k := range m {
+		r = append(r, k)
+	}
+	return mapIter[M, K, V]{m: m, keys: r}
+}
+
+func (it *mapIter[M, K, V]) next() (k K, ok, more bool) {
+	n := int(it.index.Add(1)) - 1
+	if n > len(it.keys)-1 {
+		return
+	}
+	return it.keys[n], true, n < len(it.keys)-1
+}
+
+type mapJob[M ~map[K]V, K comparable, V any] struct {
+	mapIter[M, K, V]
+	f func(K, V)
 }
 
 func (job *mapJob[M, K, V]) Next() (func(), bool) {
-	n := int(job.index.Add(1)) - 1
-	if n > len(job.keys)-1 {
+	k, ok, more := job.next()
+	if !ok {
 		return nil, false
 	}
-	k := job.keys[n]
-	return func() { job.f(k, job.m[k]) }, n < len(job.keys)-1
+	return func() { job.f(k, job.m[k]) }, more
+}
+
+type mapErrJob[M ~map[K]V, K comparable, V any] struct {
+	mapIter[M, K, V]
+	f func(K, V) error
+}
+
+func (job *mapErrJob[M, K, V]) Next() (func() error, bool) {
+	k, ok, more := job.next()
+	if !ok {
+		return nil, false
+	}
+	return func() error { return jobError(k, job.f(k, job.m[k])) }, more
+}
+
+type mapContextJob[M ~map[K]V, K comparable, V any] struct {
+	mapIter[M, K, V]
+	f func(context.Context, K, V) error
+}
+
+func (job *mapContextJob[M, K, V]) Next() (func(context.Context) error, bool) {
+	k, ok, more := job.next()
+	if !ok {
+		return nil, false
+	}
+	return func(ctx context.Context) error { return jobError(k, job.f(ctx, k, job.m[k])) }, more
 }
 
 // --- RangeJob implementation ---
-type rangeJob[T Integer] struct {
+type rangeIter[T Integer] struct {
 	start T
 	end   T
 	n     *atomic.Int64
-	f     func(T)
 }
 
-func (job *rangeJob[T]) Next() (func(), bool) {
-	if job.start < job.end {
-		if n := T(job.n.Add(1)) - 1; n <= job.end {
-			return func() { job.f(n) }, n < job.end
+func newRangeIter[T Integer](start, end T) rangeIter[T] {
+	n := new(atomic.Int64)
+	n.Store(int64(start))
+	return rangeIter[T]{start: start, end: end, n: n}
+}
+
+func (it *rangeIter[T]) next() (n T, ok, more bool) {
+	if it.start < it.end {
+		if n = T(it.n.Add(1)) - 1; n <= it.end {
+			return n, true, n < it.end
 		}
+		return
+	}
+	if n = T(it.n.Add(-1)) + 1; n >= it.end {
+		return n, true, n > it.end
+	}
+	return
+}
+
+type rangeJob[T Integer] struct {
+	rangeIter[T]
+	f func(T)
+}
+
+func (job *rangeJob[T]) Next() (func(), bool) {
+	n, ok, more := job.next()
+	if !ok {
 		return nil, false
 	}
-	if n := T(job.n.Add(-1)) + 1; n >= job.end {
-		return func() { job.f(n) }, n > job.end
+	return func() { job.f(n) }, more
+}
+
+type rangeErrJob[T Integer] struct {
+	rangeIter[T]
+	f func(T) error
+}
+
+func (job *rangeErrJob[T]) Next() (func() error, bool) {
+	n, ok, more := job.next()
+	if !ok {
+		return nil, false
+	}
+	return func() error { return jobError(n, job.f(n)) }, more
+}
+
+type rangeContextJob[T Integer] struct {
+	rangeIter[T]
+	f func(context.Context, T) error
+}
+
+func (job *rangeContextJob[T]) Next() (func(context.Context) error, bool) {
+	n, ok, more := job.next()
+	if !ok {
+		return nil, false
 	}
-	return nil, false
+	return func(ctx context.Context) error { return jobError(n, job.f(ctx, n)) }, more
 }
@@ -0,0 +1,151 @@
+package workers
+
+import (
+	"cmp"
+	"container/list"
+	"context"
+	"slices"
+)
+
+// KeyedJobList is a job list where items with the same key are processed one at a
+// time in push order, while items with different keys are processed concurrently.
+// Under WithTimeout, the next item of a key waits for the job function of a timed
+// out item to actually return.
+type KeyedJobList[K comparable, T any] struct {
+	*jobList[T]
+	q *keyedQueue[K, T]
+}
+
+// NewKeyedJobList creates a new KeyedJobList with the given Runner, key function and job function.
+// The options apply to the dispatch of the jobs as for JobList.
+func NewKeyedJobList[K comparable, T any](r Runner, key func(T) K, f func(context.Context, T) error, opts ...Option) *KeyedJobList[K, T] {
+	q := &keyedQueue[K, T]{key: key, pending: make(map[K]*list.List), running: make(map[K]bool)}
+	l := &KeyedJobList[K, T]{newJobList(q, r, f, opts), q}
+	l.release = q.done
+	l.deadLetter = deadLetter(opts, func(v T) T { return v }, false)
+	return l
+}
+
+// Push adds a job to the end of the queue of its key and signals the worker pool.
+func (l *KeyedJobList[K, T]) Push(v T) error {
+	return l.PushContext(context.Background(), v)
+}
+
+// PushContext is like Push, but gives up when ctx is done while blocked by a full job list.
+func (l *KeyedJobList[K, T]) PushContext(ctx context.Context, v T) error {
+	return l.push(ctx, func() { l.q.push(v) })
+}
+
+type keyedItem[T any] struct {
+	v   T
+	seq uint64
+}
+
+// keyedQueue holds a FIFO queue per key. A key is ready when it has pending
+// items and none of its items is running; ready keys are served in turn.
+type keyedQueue[K comparable, T any] struct {
+	key     func(T) K
+	pending map[K]*list.List
+	running map[K]bool
+	keys    list.List // ready keys
+	n       int
+	seq     uint64
+}
+
+func (q *keyedQueue[K, T]) push(v T) {
+	k := q.key(v)
+	l, ok := q.pending[k]
+	if !ok {
+		l = list.New()
+		q.pending[k] = l
+		if !q.running[k] {
+			q.keys.PushBack(k)
+		}
+	}
+	q.seq++
+	l.PushBack(keyedItem[T]{v, q.seq})
+	q.n++
+}
+
+func (q *keyedQueue[K, T]) pop() (v T, ok bool) {
+	e := q.keys.Front()
+	if e == nil {
+		return
+	}
+	k := q.keys.Remove(e).(K)
+	v = q.remove(k)
+	q.running[k] = true
+	return v, true
+}
+
+// remove removes the front item of the queue of k.
+func (q *keyedQueue[K, T]) remove(k K) T {
+	l := q.pending[k]
+	item := l.Remove(l.Front()).(keyedItem[T])
+	if l.Len() == 0 {
+		delete(q.pending, k)
+	}
+	q.n--
+	return item.v
+}
+
+func (q *keyedQueue[K, T]) dropOldest() (v T, ok bool) {
+	var oldest K
+	var seq uint64
+	for k, l := range q.pending {
+		if s := l.Front().Value.(keyedItem[T]).seq; !ok || s < seq {
+			oldest, seq, ok = k, s, true
+		}
+	}
+	if !ok {
+		return
+	}
+	v = q.remove(oldest)
+	if _, pending := q.pending[oldest]; !pending {
+		for e := q.keys.Front(); e != nil; e = e.Next() {
+			if e.Value.(K) == oldest {
+				q.keys.Remove(e)
+				break
+			}
+		}
+	}
+	return v, true
+}
+
+// done marks the key of v as not running, making it ready if it has pending items.
+func (q *keyedQueue[K, T]) done(v T) {
+	k := q.key(v)
+	if !q.running[k] {
+		return
+	}
+	delete(q.running, k)
+	if _, ok := q.pending[k]; ok {
+		q.keys.PushBack(k)
+	}
+}
+
+func (q *keyedQueue[K, T]) items() []T {
+	var items []keyedItem[T]
+	for _, l := range q.pending {
+		for e := l.Front(); e != nil; e = e.Next() {
+			items = append(items, e.Value.(keyedItem[T]))
+		}
+	}
+	slices.SortFunc(items, func(a, b keyedItem[T]) int { return cmp.Compare(a.seq, b.seq) })
+	s := make([]T, len(items))
+	for i, item := range items {
+		s[i] = item.v
+	}
+	return s
+}
+
+func (q *keyedQueue[K, T]) ready() int { return q.keys.Len() }
+
+func (q *keyedQueue[K, T]) Len() int { return q.n }
+
+func (q *keyedQueue[K, T]) clear() {
+	clear(q.pending)
+	clear(q.running)
+	q.keys.Init()
+	q.n = 0
+}
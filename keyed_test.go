@@ -0,0 +1,118 @@
+package workers
+
+import (
+	"container/list"
+	"context"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type event struct {
+	account string
+	seq     int
+}
+
+func TestKeyedJobList(t *testing.T) {
+	var mu sync.Mutex
+	res := make(map[string][]int)
+	var running, max atomic.Int64
+	var busy sync.Map
+	var wg sync.WaitGroup
+	list := NewKeyedJobList(
+		Workers(4),
+		func(e event) string { return e.account },
+		func(_ context.Context, e event) error {
+			defer wg.Done()
+			if _, loaded := busy.LoadOrStore(e.account, true); loaded {
+				t.Errorf("account %s is processed concurrently", e.account)
+			}
+			defer busy.Delete(e.account)
+			n := running.Add(1)
+			defer running.Add(-1)
+			for m := max.Load(); n > m && !max.CompareAndSwap(m, n); m = max.Load() {
+			}
+			time.Sleep(5 * time.Millisecond)
+			mu.Lock()
+			res[e.account] = append(res[e.account], e.seq)
+			mu.Unlock()
+			return nil
+		},
+	)
+	list.Start(t.Context())
+	for i := range 5 {
+		for _, account := range []string{"a", "b", "c"} {
+			wg.Add(1)
+			list.Push(event{account, i})
+		}
+	}
+	wg.Wait()
+	for _, account := range []string{"a", "b", "c"} {
+		if expect := []int{0, 1, 2, 3, 4}; !reflect.DeepEqual(expect, res[account]) {
+			t.Errorf("expected %v; got %v", expect, res[account])
+		}
+	}
+	if n := max.Load(); n < 2 || n > 3 {
+		t.Errorf("expected 2 or 3 concurrent accounts; got %d", n)
+	}
+}
+
+func TestKeyedDropOldest(t *testing.T) {
+	q := &keyedQueue[string, event]{
+		key:     func(e event) string { return e.account },
+		pending: make(map[string]*list.List),
+		running: make(map[string]bool),
+	}
+	q.push(event{"a", 1})
+	q.push(event{"b", 2})
+	q.push(event{"a", 3})
+	if v, _ := q.dropOldest(); v.seq != 1 {
+		t.Errorf("expected 1; got %d", v.seq)
+	}
+	if v, _ := q.dropOldest(); v.seq != 2 {
+		t.Errorf("expected 2; got %d", v.seq)
+	}
+	if n := q.ready(); n != 1 {
+		t.Errorf("expected 1 ready key; got %d", n)
+	}
+	if v, _ := q.pop(); v.seq != 3 {
+		t.Errorf("expected 3; got %d", v.seq)
+	}
+	if _, ok := q.pop(); ok {
+		t.Error("expected empty queue")
+	}
+}
+
+func TestKeyedTimeout(t *testing.T) {
+	var running, max atomic.Int64
+	var wg sync.WaitGroup
+	list := NewKeyedJobList(
+		Workers(4),
+		func(e event) string { return e.account },
+		func(_ context.Context, e event) error {
+			defer wg.Done()
+			n := running.Add(1)
+			defer running.Add(-1)
+			for m := max.Load(); n > m && !max.CompareAndSwap(m, n); m = max.Load() {
+			}
+			time.Sleep(20 * time.Millisecond)
+			return nil
+		},
+		WithTimeout(5*time.Millisecond),
+		WithDeadLetter(func(DeadLetter[event]) {}),
+	)
+	list.Start(t.Context())
+	for i := range 4 {
+		wg.Add(1)
+		list.Push(event{"a", i})
+	}
+	wg.Wait()
+	if n := max.Load(); n != 1 {
+		t.Errorf("expected items of a key to run one at a time; got %d", n)
+	}
+	if s := list.Stats(); s.Failed != 4 {
+		t.Errorf("expected 4 timed out items; got %d", s.Failed)
+	}
+}
@@ -0,0 +1,106 @@
+package workers
+
+import "errors"
+
+// State is the lifecycle state of a job list.
+type State int
+
+const (
+	// StateStopped is the state of a job list not started yet, or stopped by Stop.
+	// Items can be pushed and are kept until it is started.
+	StateStopped State = iota
+	// StateRunning is the state of a started job list.
+	StateRunning
+	// StatePaused is the state of a job list paused by Pause. No item is dispatched
+	// until it is resumed, while the running ones continue.
+	StatePaused
+	// StateClosed is the state of a job list closed by Close or Shutdown. It is final.
+	StateClosed
+)
+
+func (s State) String() string {
+	switch s {
+	case StateStopped:
+		return "stopped"
+	case StateRunning:
+		return "running"
+	case StatePaused:
+		return "paused"
+	case StateClosed:
+		return "closed"
+	}
+	return "unknown"
+}
+
+// State returns the lifecycle state of the job list.
+func (l *jobList[T]) State() State {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.stateLocked()
+}
+
+func (l *jobList[T]) stateLocked() State {
+	switch {
+	case l.closed:
+		return StateClosed
+	case l.c == nil:
+		return StateStopped
+	case l.paused:
+		return StatePaused
+	}
+	return StateRunning
+}
+
+// Stop stops dispatching jobs, keeping the pending ones until the job list is
+// started again. The running jobs are not affected.
+func (l *jobList[T]) Stop() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return errors.New("job list is closed")
+	}
+	if l.c == nil {
+		return errors.New("job list is not started")
+	}
+	l.stopLocked()
+	return nil
+}
+
+// stop stops the run signaled by sig, if it is still the current one.
+func (l *jobList[T]) stop(sig chan struct{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.c == sig && !l.closed {
+		l.stopLocked()
+	}
+}
+
+func (l *jobList[T]) stopLocked() {
+	close(l.c)
+	l.c = nil
+	l.paused = false
+}
+
+// Pause stops dispatching jobs until Resume is called. The pending jobs are kept
+// and the running ones are not affected.
+func (l *jobList[T]) Pause() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if state := l.stateLocked(); state != StateRunning {
+		return errors.New("job list is " + state.String())
+	}
+	l.paused = true
+	return nil
+}
+
+// Resume resumes dispatching jobs after Pause.
+func (l *jobList[T]) Resume() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if state := l.stateLocked(); state != StatePaused {
+		return errors.New("job list is " + state.String())
+	}
+	l.paused = false
+	l.signal()
+	return nil
+}
@@ -0,0 +1,84 @@
+package workers
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestJobListLifecycle(t *testing.T) {
+	var mu sync.Mutex
+	var res []int
+	list := NewJobList(2, func(i int) {
+		mu.Lock()
+		res = append(res, i)
+		mu.Unlock()
+	})
+	state := func(expect State) {
+		t.Helper()
+		if s := list.State(); s != expect {
+			t.Errorf("expected %s; got %s", expect, s)
+		}
+	}
+	wait := func() {
+		t.Helper()
+		for list.Len() != 0 || list.Running() != 0 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	state(StateStopped)
+	list.PushBack(1)
+	if err := list.Pause(); err == nil {
+		t.Error("expected error pausing a stopped job list; got nil")
+	}
+	list.Start(t.Context())
+	state(StateRunning)
+	wait()
+
+	list.Pause()
+	state(StatePaused)
+	list.PushBack(2)
+	list.PushBack(3)
+	time.Sleep(10 * time.Millisecond)
+	if expect := []int{2, 3}; !reflect.DeepEqual(expect, slices.Collect(list.Pending())) {
+		t.Errorf("expected %v; got %v", expect, slices.Collect(list.Pending()))
+	}
+	list.Resume()
+	state(StateRunning)
+	wait()
+
+	list.Stop()
+	state(StateStopped)
+	list.PushBack(4)
+	time.Sleep(10 * time.Millisecond)
+	if n := list.Len(); n != 1 {
+		t.Errorf("expected 1 pending; got %d", n)
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	if err := list.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	wait()
+	cancel()
+	for list.State() != StateStopped {
+		time.Sleep(time.Millisecond)
+	}
+	list.PushBack(5)
+	list.Start(t.Context())
+	if _, err := list.Shutdown(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	state(StateClosed)
+	if err := list.Start(t.Context()); err == nil {
+		t.Error("expected error starting a closed job list; got nil")
+	}
+	slices.Sort(res[1:3])
+	if expect := []int{1, 2, 3, 4, 5}; !reflect.DeepEqual(expect, res) {
+		t.Errorf("expected %v; got %v", expect, res)
+	}
+}
@@ -0,0 +1,124 @@
+package workers
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// limiter limits the number of jobs running at the same time.
+type limiter interface {
+	Acquire(ctx context.Context, n int64) error
+	Release(n int64)
+}
+
+var _ limiter = new(resizableLimiter)
+
+type waiter struct {
+	n     int64
+	ready chan struct{}
+}
+
+// resizableLimiter is a weighted semaphore whose size can be changed at any time.
+// Like semaphore.Weighted, waiters are served in FIFO order. A weight larger than
+// the current size, e.g. after shrinking, is admitted once nothing else is held.
+type resizableLimiter struct {
+	mu      sync.Mutex
+	size    int64
+	cur     int64
+	waiters list.List
+}
+
+func newResizableLimiter(n int64) *resizableLimiter {
+	return &resizableLimiter{size: n}
+}
+
+// Acquire acquires n from the limiter, blocking until it is available or ctx is done.
+func (l *resizableLimiter) Acquire(ctx context.Context, n int64) error {
+	done := ctx.Done()
+	l.mu.Lock()
+	select {
+	case <-done:
+		l.mu.Unlock()
+		return ctx.Err()
+	default:
+	}
+	if l.fits(n) && l.waiters.Len() == 0 {
+		l.cur += n
+		l.mu.Unlock()
+		return nil
+	}
+	ready := make(chan struct{})
+	elem := l.waiters.PushBack(waiter{n: n, ready: ready})
+	l.mu.Unlock()
+
+	select {
+	case <-done:
+		l.mu.Lock()
+		select {
+		case <-ready:
+			// Acquired after ctx is done, give it back.
+			l.cur -= n
+			l.notifyWaiters()
+		default:
+			isFront := l.waiters.Front() == elem
+			l.waiters.Remove(elem)
+			if isFront {
+				l.notifyWaiters()
+			}
+		}
+		l.mu.Unlock()
+		return ctx.Err()
+	case <-ready:
+		return nil
+	}
+}
+
+// Release releases n to the limiter.
+func (l *resizableLimiter) Release(n int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.cur -= n
+	if l.cur < 0 {
+		panic("workers: released more than held")
+	}
+	l.notifyWaiters()
+}
+
+// Resize changes the size of the limiter. Growing admits waiters immediately,
+// while shrinking lets holders keep what they acquired until they release it.
+func (l *resizableLimiter) Resize(n int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.size = n
+	l.notifyWaiters()
+}
+
+// Size returns the current size of the limiter.
+func (l *resizableLimiter) Size() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.size
+}
+
+func (l *resizableLimiter) notifyWaiters() {
+	for {
+		next := l.waiters.Front()
+		if next == nil {
+			break
+		}
+		w := next.Value.(waiter)
+		if !l.fits(w.n) {
+			break
+		}
+		l.cur += w.n
+		l.waiters.Remove(next)
+		close(w.ready)
+	}
+}
+
+// fits reports whether n can be acquired now, counting a weight larger than
+// the size as the size.
+func (l *resizableLimiter) fits(n int64) bool {
+	return l.size-l.cur >= min(n, l.size)
+}
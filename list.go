@@ -1,31 +1,108 @@
 package workers
 
 import (
+	"container/heap"
 	"context"
 	"errors"
+	"iter"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/sunshineplan/utils/container"
 )
 
-// JobList is a struct that holds a list of jobs, a worker pool, a function to execute jobs,
-// a channel for signaling, and a boolean indicating if the job list is closed.
-type JobList[T any] struct {
+// queue holds the pending items of a job list. Its methods are called with the
+// job list lock held.
+type queue[T any] interface {
+	Len() int
+	// ready returns the number of items which can be popped now.
+	ready() int
+	pop() (T, bool)
+	// dropOldest removes the item which has been waiting the longest.
+	dropOldest() (T, bool)
+	// items returns the pending items in the order they would be popped,
+	// as far as it is known.
+	items() []T
+	clear()
+}
+
+// ErrFull is returned when pushing to a full job list under OverflowError.
+var ErrFull = errors.New("job list is full")
+
+// OverflowPolicy defines what happens when pushing to a job list at capacity.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock blocks the push until there is room, the context is done or the job list is closed.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowError rejects the push with ErrFull.
+	OverflowError
+	// OverflowDropOldest drops the item which has been waiting the longest to make room.
+	OverflowDropOldest
+	// OverflowDropNewest drops the pushed item.
+	OverflowDropNewest
+)
+
+// WithCapacity bounds the number of pending items of a job list to n, applying
+// the given policy when it is full. It has no effect on Run and Listen.
+func WithCapacity(n int, policy OverflowPolicy) Option {
+	return func(c *config) {
+		c.capacity = n
+		c.overflow = policy
+	}
+}
+
+// jobList is the common implementation of the job lists, which differ in their queue.
+type jobList[T any] struct {
 	mu     sync.Mutex
-	l      *container.List[T]
-	w      Workers
-	f      func(T)
+	l      queue[T]
+	r      Runner
+	f      func(context.Context, T) error
+	done   func(T, error) // called after an item is processed, with the lock held
 	c      chan struct{}
+	opts   []Option
+	cfg    *config
 	closed bool
+	paused bool
+
+	deadLetter func(context.Context, T, error, int) // called with the items failing after their retries
+	count      func(T) int64                        // number of items in a queued value for Stats, 1 if nil
+	release    func(T)                              // called once the job function returned for an item, with the lock held
+
+	running  int
+	shutdown chan struct{} // closed when idle after Shutdown is called
+
+	capacity int
+	overflow OverflowPolicy
+	space    chan struct{} // closed when items leave the queue
+
+	pushed, started, completed, failed, dropped atomic.Int64
 }
 
-// NewJobList creates a new JobList with the given worker pool and job function.
-func NewJobList[T any](workers int, f func(T)) *JobList[T] {
-	return &JobList[T]{l: container.NewList[T](), w: Workers(workers), f: f}
+// Stats holds the cumulative counters of a job list.
+type Stats struct {
+	// Pushed is the number of items accepted by the job list.
+	Pushed int64
+	// Started is the number of items which started running.
+	Started int64
+	// Completed is the number of items which ran successfully.
+	Completed int64
+	// Failed is the number of items which failed, after retries if any.
+	Failed int64
+	// Dropped is the number of items dropped by the overflow policy.
+	Dropped int64
+}
+
+func newJobList[T any](q queue[T], r Runner, f func(context.Context, T) error, opts []Option) *jobList[T] {
+	cfg := newConfig(opts)
+	return &jobList[T]{l: q, r: r, f: f, opts: opts, cfg: cfg, capacity: cfg.capacity, overflow: cfg.overflow}
 }
 
 // Start begins processing jobs in the job list using the provided context.
-func (l *JobList[T]) Start(ctx context.Context) error {
+// When ctx is done, the job list is stopped as by Stop and can be started again.
+func (l *jobList[T]) Start(ctx context.Context) error {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 	if l.closed {
@@ -34,79 +111,549 @@ func (l *JobList[T]) Start(ctx context.Context) error {
 	if l.c != nil {
 		return errors.New("job list is already started")
 	}
-	l.c = make(chan struct{}, 1)
+	sig := make(chan struct{}, 1)
+	l.c, l.paused = sig, false
 	c := make(chan func())
-	l.w.Listen(ctx, c)
+	// Items are retried and timed by the job list itself, which reports their
+	// failures, so the listener has nothing to report.
+	l.r.Listen(ctx, c, append(slices.Clip(l.opts), func(c *config) { c.retry, c.timeout = nil, 0 })...)
 	go func() {
 		defer close(c)
 		for {
 			select {
 			case <-ctx.Done():
-				l.Close()
+				l.stop(sig)
 				return
-			case _, ok := <-l.c:
+			case _, ok := <-sig:
 				if !ok {
 					return
 				}
-				for {
-					e := l.l.Front()
-					if e == nil {
-						break
+				// Items are popped when a worker is ready, so that the queue decides
+				// which item runs next. A job finding the queue empty, or belonging to
+				// a stopped run, does nothing.
+				for l.ready(sig) > 0 {
+					select {
+					case <-ctx.Done():
+						l.stop(sig)
+						return
+					case _, ok := <-sig:
+						if !ok {
+							return
+						}
+					case c <- func() {
+						if v, ok := l.pop(sig); ok {
+							l.do(ctx, v)
+						}
+					}:
 					}
-					v := l.l.Remove(e)
-					c <- func() { l.f(v) }
 				}
 			}
 		}
 	}()
+	l.signal()
 	return nil
 }
 
+// do processes v, retrying and timing it as configured. A panic is recovered as a
+// *PanicError and handled like any other failure, then re-panics under PanicRepanic.
+func (l *jobList[T]) do(ctx context.Context, v T) {
+	var err error
+	var attempts int
+	var calls sync.WaitGroup // calls of l.f, which outlive do if they timed out
+	defer func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.running--
+		if l.done != nil {
+			l.done(v, err)
+			l.signal()
+		}
+		if l.release != nil {
+			if l.cfg.timeout > 0 {
+				go func() {
+					calls.Wait()
+					l.mu.Lock()
+					defer l.mu.Unlock()
+					l.release(v)
+					l.signal()
+				}()
+			} else {
+				l.release(v)
+				l.signal()
+			}
+		}
+		l.checkIdle()
+	}()
+	err = l.cfg.retried(ctx, func(ctx context.Context) error {
+		attempts++
+		calls.Add(1)
+		return l.cfg.timed(ctx, func(ctx context.Context) (err error) {
+			defer calls.Done()
+			defer func() {
+				if p := recover(); p != nil {
+					err = l.cfg.panicError(p)
+				}
+			}()
+			return l.f(ctx, v)
+		})
+	})
+	if err == nil {
+		l.completed.Add(l.items(v))
+		return
+	}
+	l.failed.Add(l.items(v))
+	l.deadLetter(ctx, v, err, attempts)
+	if e, ok := err.(*PanicError); ok && l.cfg.panicPolicy == PanicRepanic {
+		// The listener re-panics with e without handling it again.
+		panic(handledPanic{e})
+	}
+}
+
+// items returns the number of items in v.
+func (l *jobList[T]) items(v T) int64 {
+	if l.count == nil {
+		return 1
+	}
+	return l.count(v)
+}
+
+// Len returns the number of pending items.
+func (l *jobList[T]) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.l.Len()
+}
+
+// Running returns the number of items being processed.
+func (l *jobList[T]) Running() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.running
+}
+
+// Pending returns an iterator over a snapshot of the pending items,
+// in the order they would run as far as it is known.
+func (l *jobList[T]) Pending() iter.Seq[T] {
+	l.mu.Lock()
+	items := l.l.items()
+	l.mu.Unlock()
+	return slices.Values(items)
+}
+
+// Stats returns the cumulative counters of the job list.
+func (l *jobList[T]) Stats() Stats {
+	return Stats{
+		Pushed:    l.pushed.Load(),
+		Started:   l.started.Load(),
+		Completed: l.completed.Load(),
+		Failed:    l.failed.Load(),
+		Dropped:   l.dropped.Load(),
+	}
+}
+
+// ready returns the number of items which can be dispatched by the run signaled by sig.
+func (l *jobList[T]) ready(sig chan struct{}) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.c != sig || l.paused {
+		return 0
+	}
+	return l.l.ready()
+}
+
+func (l *jobList[T]) pop(sig chan struct{}) (v T, ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.c != sig || l.paused {
+		return
+	}
+	if v, ok = l.l.pop(); ok {
+		l.running++
+		l.started.Add(l.items(v))
+		l.freed()
+	}
+	return
+}
+
+// freed wakes up the pushes blocked by a full queue. It must be called with the lock held.
+func (l *jobList[T]) freed() {
+	if l.space != nil {
+		close(l.space)
+		l.space = nil
+	}
+}
+
+// Shutdown stops accepting new jobs, then waits for the pending jobs to be processed
+// and the running ones to return before closing the job list. If ctx is done first,
+// the job list is closed and the jobs still pending are returned with the context error.
+// A paused job list is resumed, while a stopped one is closed at once, returning its
+// pending jobs.
+func (l *jobList[T]) Shutdown(ctx context.Context) ([]T, error) {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil, errors.New("job list is already closed")
+	}
+	if l.c == nil {
+		items := l.l.items()
+		l.mu.Unlock()
+		l.Close()
+		return items, nil
+	}
+	if l.paused {
+		l.paused = false
+		l.signal()
+	}
+	if l.shutdown == nil {
+		l.shutdown = make(chan struct{})
+		l.freed()
+		l.checkIdle()
+	}
+	idle := l.shutdown
+	l.mu.Unlock()
+
+	select {
+	case <-idle:
+		l.Close()
+		return nil, nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		items := l.l.items()
+		l.mu.Unlock()
+		l.Close()
+		return items, ctx.Err()
+	}
+}
+
+// checkIdle closes l.shutdown if shutting down and nothing is pending or running.
+// It must be called with the lock held.
+func (l *jobList[T]) checkIdle() {
+	if l.shutdown == nil || l.running > 0 || l.l.Len() > 0 {
+		return
+	}
+	select {
+	case <-l.shutdown:
+	default:
+		close(l.shutdown)
+	}
+}
+
 // Close stops processing jobs and clears the job list.
-func (l *JobList[T]) Close() error {
+func (l *jobList[T]) Close() error {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 	if l.closed {
 		return errors.New("job list is already closed")
 	}
-	l.l.Init()
-	close(l.c)
+	l.l.clear()
+	l.freed()
+	if l.c != nil {
+		close(l.c)
+	}
 	l.closed = true
 	return nil
 }
 
-// PushBack adds a job to the end of the job list and signals the worker pool.
-func (l *JobList[T]) PushBack(v T) error {
+// push adds a job to the queue with f and signals the worker pool.
+// If the queue is full, it applies the overflow policy, blocking until ctx is done under OverflowBlock.
+func (l *jobList[T]) push(ctx context.Context, f func()) error {
 	l.mu.Lock()
 	defer l.mu.Unlock()
-	if l.closed {
-		return errors.New("job list is closed")
+	for {
+		if l.closed {
+			return errors.New("job list is closed")
+		}
+		if l.shutdown != nil {
+			return errors.New("job list is shutting down")
+		}
+		if l.capacity <= 0 || l.l.Len() < l.capacity {
+			break
+		}
+		switch l.overflow {
+		case OverflowError:
+			return ErrFull
+		case OverflowDropNewest:
+			l.dropped.Add(1)
+			return nil
+		case OverflowDropOldest:
+			if _, ok := l.l.dropOldest(); ok {
+				l.dropped.Add(1)
+			}
+			continue
+		}
+		if l.space == nil {
+			l.space = make(chan struct{})
+		}
+		space := l.space
+		l.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			l.mu.Lock()
+			return ctx.Err()
+		case <-space:
+		}
+		l.mu.Lock()
 	}
-	if l.c == nil {
-		return errors.New("job list is not started")
+	f()
+	l.pushed.Add(1)
+	l.signal()
+	return nil
+}
+
+// signal wakes up the dispatcher. It must be called with the lock held.
+func (l *jobList[T]) signal() {
+	if l.closed || l.c == nil {
+		return
 	}
-	l.l.PushBack(v)
 	select {
 	case l.c <- struct{}{}:
 	default:
 	}
-	return nil
+}
+
+// ErrCanceled is the result of an item removed from a job list by Handle.Cancel.
+var ErrCanceled = errors.New("job was canceled")
+
+// ErrDropped is the result of an item removed from a job list without running,
+// by the overflow policy or by Close.
+var ErrDropped = errors.New("job was dropped")
+
+// JobList is a struct that holds a list of jobs, a worker pool, a function to execute jobs,
+// a channel for signaling, and a boolean indicating if the job list is closed.
+type JobList[T any] struct {
+	*jobList[*Handle[T]]
+	q *listQueue[T]
+}
+
+// NewJobList creates a new JobList with the given worker pool and job function.
+// The options apply to the dispatch of the jobs, as in Workers.Listen.
+func NewJobList[T any](workers int, f func(T), opts ...Option) *JobList[T] {
+	return NewJobListWith(Workers(workers), f, opts...)
+}
+
+// NewJobListWith creates a new JobList with the given Runner and job function.
+// Use a *Pool to share its limit with other users.
+func NewJobListWith[T any](r Runner, f func(T), opts ...Option) *JobList[T] {
+	return NewErrJobListWith(r, func(_ context.Context, v T) error { f(v); return nil }, opts...)
+}
+
+// NewErrJobList creates a new JobList with the given worker pool and error-returning job function.
+// The function receives the context passed to Start, carrying the attempt number under WithRetry.
+// Items still failing after the retries are reported by their Handle, and to the
+// dead-letter sink set with WithDeadLetter, WithDeadLetterChan or WithDeadLetterList.
+func NewErrJobList[T any](workers int, f func(context.Context, T) error, opts ...Option) *JobList[T] {
+	return NewErrJobListWith(Workers(workers), f, opts...)
+}
+
+// NewErrJobListWith creates a new JobList with the given Runner and error-returning job function.
+func NewErrJobListWith[T any](r Runner, f func(context.Context, T) error, opts ...Option) *JobList[T] {
+	q := &listQueue[T]{List: container.NewList[*Handle[T]]()}
+	l := &JobList[T]{newJobList(q, r, func(ctx context.Context, h *Handle[T]) error { return f(ctx, h.v) }, opts), q}
+	l.done = func(h *Handle[T], err error) { h.resolve(err) }
+	l.deadLetter = deadLetter(opts, (*Handle[T]).Value, true)
+	q.timer = time.AfterFunc(time.Hour, l.promote)
+	q.timer.Stop()
+	return l
+}
+
+// PushBack adds a job to the end of the job list and signals the worker pool.
+// The returned Handle tracks the job.
+func (l *JobList[T]) PushBack(v T) (*Handle[T], error) {
+	return l.PushBackContext(context.Background(), v)
+}
+
+// PushBackContext is like PushBack, but gives up when ctx is done while blocked by a full job list.
+func (l *JobList[T]) PushBackContext(ctx context.Context, v T) (*Handle[T], error) {
+	return l.pushHandle(ctx, v, func(h *Handle[T]) { h.e = l.q.PushBack(h) })
 }
 
 // PushFront adds a job to the front of the job list and signals the worker pool.
-func (l *JobList[T]) PushFront(v T) error {
+// The returned Handle tracks the job.
+func (l *JobList[T]) PushFront(v T) (*Handle[T], error) {
+	return l.PushFrontContext(context.Background(), v)
+}
+
+// PushFrontContext is like PushFront, but gives up when ctx is done while blocked by a full job list.
+func (l *JobList[T]) PushFrontContext(ctx context.Context, v T) (*Handle[T], error) {
+	return l.pushHandle(ctx, v, func(h *Handle[T]) { h.e = l.q.PushFront(h) })
+}
+
+func (l *JobList[T]) pushHandle(ctx context.Context, v T, insert func(*Handle[T])) (*Handle[T], error) {
+	h := &Handle[T]{v: v, l: l, index: -1, done: make(chan struct{})}
+	var queued bool
+	if err := l.push(ctx, func() { insert(h); queued = true }); err != nil {
+		return nil, err
+	}
+	if !queued {
+		h.resolve(ErrDropped)
+	}
+	return h, nil
+}
+
+// Pending returns an iterator over a snapshot of the pending items, in the order they would run,
+// followed by the delayed items in the order they are due.
+func (l *JobList[T]) Pending() iter.Seq[T] {
+	return values(l.jobList.Pending())
+}
+
+// Shutdown stops accepting new jobs, then waits for the pending jobs to be processed
+// and the running ones to return before closing the job list. If ctx is done first,
+// the job list is closed and the jobs still pending are returned with the context error.
+// Delayed items are pending until they are due.
+func (l *JobList[T]) Shutdown(ctx context.Context) ([]T, error) {
+	hs, err := l.jobList.Shutdown(ctx)
+	if hs == nil {
+		return nil, err
+	}
+	return slices.Collect(values(slices.Values(hs))), err
+}
+
+func values[T any](seq iter.Seq[*Handle[T]]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for h := range seq {
+			if !yield(h.v) {
+				return
+			}
+		}
+	}
+}
+
+// Handle tracks an item pushed to a JobList.
+type Handle[T any] struct {
+	v T
+	l *JobList[T]
+	e *container.Element[*Handle[T]] // nil unless the item is in the queue, guarded by the job list lock
+
+	at    time.Time // when a delayed item is due
+	seq   uint64
+	index int // index in the delayed heap, or -1
+
+	done chan struct{}
+	err  error
+}
+
+// Value returns the item.
+func (h *Handle[T]) Value() T { return h.v }
+
+// Cancel removes the item from the job list if it has not been dispatched yet,
+// reporting whether it was removed. The result of a removed item is ErrCanceled.
+func (h *Handle[T]) Cancel() bool {
+	l := h.l
 	l.mu.Lock()
 	defer l.mu.Unlock()
-	if l.closed {
-		return errors.New("job list is closed")
+	if !l.q.remove(h) {
+		return false
 	}
-	if l.c == nil {
-		return errors.New("job list is not started")
+	h.resolve(ErrCanceled)
+	l.freed()
+	l.checkIdle()
+	return true
+}
+
+// Done returns a channel which is closed when the item has been processed or removed.
+func (h *Handle[T]) Done() <-chan struct{} { return h.done }
+
+// Err returns the result of the item once Done is closed: nil if it ran successfully,
+// the error of the job function, ErrCanceled or ErrDropped. It returns nil before.
+func (h *Handle[T]) Err() error {
+	select {
+	case <-h.done:
+		return h.err
+	default:
+		return nil
 	}
-	l.l.PushFront(v)
+}
+
+// Wait waits for the item to be processed or removed and returns its result as Err,
+// or the context error if ctx is done first.
+func (h *Handle[T]) Wait(ctx context.Context) error {
 	select {
-	case l.c <- struct{}{}:
+	case <-h.done:
+		return h.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (h *Handle[T]) resolve(err error) {
+	h.err = err
+	close(h.done)
+}
+
+// listQueue is a FIFO queue of handles backed by a container.List,
+// with the delayed handles waiting in a heap until they are due.
+type listQueue[T any] struct {
+	*container.List[*Handle[T]]
+	delayed delayQueue[T]
+	timer   *time.Timer
+	seq     uint64
+}
+
+func (q *listQueue[T]) Len() int { return q.List.Len() + len(q.delayed) }
+
+func (q *listQueue[T]) pop() (*Handle[T], bool) {
+	e := q.Front()
+	if e == nil {
+		return nil, false
+	}
+	h := q.Remove(e)
+	h.e = nil
+	return h, true
+}
+
+func (q *listQueue[T]) ready() int { return q.List.Len() }
+
+// dropOldest removes the front item, which is the oldest unless it was pushed by PushFront,
+// or the delayed item due first if none is ready.
+func (q *listQueue[T]) dropOldest() (*Handle[T], bool) {
+	h, ok := q.pop()
+	if !ok && len(q.delayed) > 0 {
+		h = q.delayed[0]
+		ok = q.remove(h)
+	}
+	if ok {
+		h.resolve(ErrDropped)
+	}
+	return h, ok
+}
+
+func (q *listQueue[T]) items() (s []*Handle[T]) {
+	for e := q.Front(); e != nil; e = e.Next() {
+		s = append(s, e.Value())
+	}
+	delayed := slices.Clone(q.delayed)
+	slices.SortFunc(delayed, (*Handle[T]).compare)
+	return append(s, delayed...)
+}
+
+// remove removes h if it is still pending, reporting whether it was.
+func (q *listQueue[T]) remove(h *Handle[T]) bool {
+	switch {
+	case h.e != nil:
+		q.Remove(h.e)
+		h.e = nil
+	case h.index >= 0:
+		top := h.index == 0
+		heap.Remove(&q.delayed, h.index)
+		if top {
+			q.schedule()
+		}
 	default:
+		return false
 	}
-	return nil
+	return true
+}
+
+func (q *listQueue[T]) clear() {
+	for e := q.Front(); e != nil; e = e.Next() {
+		e.Value().e = nil
+		e.Value().resolve(ErrDropped)
+	}
+	q.Init()
+	for _, h := range q.delayed {
+		h.index = -1
+		h.resolve(ErrDropped)
+	}
+	q.delayed = nil
+	q.timer.Stop()
 }
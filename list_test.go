@@ -2,10 +2,12 @@ package workers
 
 import (
 	"context"
+	"errors"
 	"log"
 	"reflect"
 	"slices"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -46,3 +48,310 @@ func TestJobList(t *testing.T) {
 		t.Errorf("expected %v; got %v", expect, res)
 	}
 }
+
+func TestJobListCapacity(t *testing.T) {
+	newList := func(policy OverflowPolicy) (*JobList[int], *[]int, chan struct{}, *sync.WaitGroup) {
+		var mu sync.Mutex
+		var res []int
+		var wg sync.WaitGroup
+		block := make(chan struct{})
+		list := NewJobList(1, func(i int) {
+			defer wg.Done()
+			if i == 0 {
+				<-block
+				return
+			}
+			mu.Lock()
+			res = append(res, i)
+			mu.Unlock()
+		}, WithCapacity(2, policy))
+		list.Start(t.Context())
+		wg.Add(1)
+		list.PushBack(0)
+		for list.Len() != 0 {
+			time.Sleep(time.Millisecond)
+		}
+		wg.Add(2)
+		list.PushBack(1)
+		list.PushBack(2)
+		return list, &res, block, &wg
+	}
+
+	list, res, block, wg := newList(OverflowError)
+	if _, err := list.PushBack(3); err != ErrFull {
+		t.Errorf("expected %v; got %v", ErrFull, err)
+	}
+	close(block)
+	wg.Wait()
+	if expect := []int{1, 2}; !reflect.DeepEqual(expect, *res) {
+		t.Errorf("expected %v; got %v", expect, *res)
+	}
+
+	for _, tc := range []struct {
+		policy OverflowPolicy
+		expect []int
+	}{
+		{OverflowDropOldest, []int{2, 3}},
+		{OverflowDropNewest, []int{1, 2}},
+	} {
+		list, res, block, wg := newList(tc.policy)
+		h, err := list.PushBack(3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.policy == OverflowDropNewest {
+			if err := h.Err(); err != ErrDropped {
+				t.Errorf("expected %v; got %v", ErrDropped, err)
+			}
+		}
+		if n := list.Stats().Dropped; n != 1 {
+			t.Errorf("expected 1 dropped; got %d", n)
+		}
+		close(block)
+		wg.Wait()
+		if !reflect.DeepEqual(tc.expect, *res) {
+			t.Errorf("expected %v; got %v", tc.expect, *res)
+		}
+	}
+
+	list, res, block, wg = newList(OverflowBlock)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := list.PushBackContext(ctx, 3); err != context.DeadlineExceeded {
+		t.Errorf("expected %v; got %v", context.DeadlineExceeded, err)
+	}
+	wg.Add(1)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(block)
+	}()
+	if _, err := list.PushBack(3); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+	if expect := []int{1, 2, 3}; !reflect.DeepEqual(expect, *res) {
+		t.Errorf("expected %v; got %v", expect, *res)
+	}
+}
+
+func TestJobListShutdown(t *testing.T) {
+	var n atomic.Int64
+	list := NewJobList(1, func(int) {
+		time.Sleep(10 * time.Millisecond)
+		n.Add(1)
+	})
+	list.Start(t.Context())
+	for i := range 5 {
+		list.PushBack(i)
+	}
+	if items, err := list.Shutdown(context.Background()); err != nil || items != nil {
+		t.Fatalf("expected nil; got %v, %v", items, err)
+	}
+	if n := n.Load(); n != 5 {
+		t.Errorf("expected 5 processed jobs; got %d", n)
+	}
+	if _, err := list.PushBack(5); err == nil {
+		t.Error("expected error; got nil")
+	}
+
+	block := make(chan struct{})
+	defer close(block)
+	list = NewJobList(1, func(int) { <-block })
+	list.Start(t.Context())
+	list.PushBack(0)
+	for list.Len() != 0 {
+		time.Sleep(time.Millisecond)
+	}
+	for i := range 4 {
+		list.PushBack(i + 1)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	items, err := list.Shutdown(ctx)
+	if err != context.DeadlineExceeded {
+		t.Errorf("expected %v; got %v", context.DeadlineExceeded, err)
+	}
+	if expect := []int{1, 2, 3, 4}; !reflect.DeepEqual(expect, items) {
+		t.Errorf("expected %v; got %v", expect, items)
+	}
+}
+
+func TestJobListStats(t *testing.T) {
+	block := make(chan struct{})
+	list := NewErrJobList(1, func(_ context.Context, i int) error {
+		if i == 0 {
+			<-block
+		}
+		if i%2 == 1 {
+			return errors.New("odd")
+		}
+		return nil
+	}, WithCapacity(3, OverflowDropNewest))
+	list.Start(t.Context())
+	list.PushBack(0)
+	for list.Len() != 0 {
+		time.Sleep(time.Millisecond)
+	}
+	for i := range 4 {
+		list.PushBack(i + 1)
+	}
+	if n := list.Len(); n != 3 {
+		t.Errorf("expected 3; got %d", n)
+	}
+	if n := list.Running(); n != 1 {
+		t.Errorf("expected 1; got %d", n)
+	}
+	if expect := []int{1, 2, 3}; !reflect.DeepEqual(expect, slices.Collect(list.Pending())) {
+		t.Errorf("expected %v; got %v", expect, slices.Collect(list.Pending()))
+	}
+	close(block)
+	if _, err := list.Shutdown(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if expect := (Stats{Pushed: 4, Started: 4, Completed: 2, Failed: 2, Dropped: 1}); list.Stats() != expect {
+		t.Errorf("expected %+v; got %+v", expect, list.Stats())
+	}
+	if n := list.Running(); n != 0 {
+		t.Errorf("expected 0; got %d", n)
+	}
+}
+
+func TestJobListHandle(t *testing.T) {
+	block := make(chan struct{})
+	var mu sync.Mutex
+	var res []int
+	list := NewErrJobList(1, func(_ context.Context, i int) error {
+		if i == 0 {
+			<-block
+		}
+		if i == 3 {
+			return errors.New("three")
+		}
+		mu.Lock()
+		res = append(res, i)
+		mu.Unlock()
+		return nil
+	})
+	list.Start(t.Context())
+	h0, _ := list.PushBack(0)
+	for list.Len() != 0 {
+		time.Sleep(time.Millisecond)
+	}
+	var hs []*Handle[int]
+	for i := range 3 {
+		h, err := list.PushBack(i + 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hs = append(hs, h)
+	}
+	if h0.Cancel() {
+		t.Error("expected running item not to be canceled")
+	}
+	if !hs[1].Cancel() {
+		t.Error("expected pending item to be canceled")
+	}
+	if hs[1].Cancel() {
+		t.Error("expected item to be canceled once")
+	}
+	if err := hs[1].Err(); err != ErrCanceled {
+		t.Errorf("expected %v; got %v", ErrCanceled, err)
+	}
+	if err := hs[0].Err(); err != nil {
+		t.Errorf("expected nil; got %v", err)
+	}
+	close(block)
+	if err := h0.Wait(t.Context()); err != nil {
+		t.Errorf("expected nil; got %v", err)
+	}
+	if err := hs[2].Wait(t.Context()); err == nil || err.Error() != "three" {
+		t.Errorf("expected three; got %v", err)
+	}
+	<-hs[0].Done()
+	if expect := []int{0, 1}; !reflect.DeepEqual(expect, res) {
+		t.Errorf("expected %v; got %v", expect, res)
+	}
+
+	list.Close()
+	if _, err := list.PushBack(4); err == nil {
+		t.Error("expected error; got nil")
+	}
+}
+
+func TestJobListPanic(t *testing.T) {
+	var dead []DeadLetter[int]
+	var handled atomic.Int64
+	list := NewErrJobList(1, func(_ context.Context, i int) error {
+		if i == 1 {
+			panic("boom")
+		}
+		return nil
+	},
+		WithRetry(RetryPolicy{MaxAttempts: 2}),
+		WithPanicHandler(func(any, []byte) { handled.Add(1) }),
+		WithDeadLetter(func(d DeadLetter[int]) { dead = append(dead, d) }),
+	)
+	list.Start(t.Context())
+	h, _ := list.PushBack(1)
+	list.PushBack(2)
+	var pe *PanicError
+	if err := h.Wait(t.Context()); !errors.As(err, &pe) || pe.Value != "boom" {
+		t.Errorf("expected panic error; got %v", err)
+	}
+	if _, err := list.Shutdown(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if expect := (Stats{Pushed: 2, Started: 2, Completed: 1, Failed: 1}); list.Stats() != expect {
+		t.Errorf("expected %+v; got %+v", expect, list.Stats())
+	}
+	if n := handled.Load(); n != 2 {
+		t.Errorf("expected 2 handled panics; got %d", n)
+	}
+	if len(dead) != 1 || dead[0].Item != 1 || dead[0].Attempts != 2 {
+		t.Errorf("expected item 1 after 2 attempts in dead letters; got %v", dead)
+	}
+
+	// Under PanicRepanic, the listener running the item re-panics with the
+	// *PanicError of the item, handled once.
+	handled.Store(0)
+	list = NewErrJobList(1, func(context.Context, int) error { panic("boom") },
+		WithPanicPolicy(PanicRepanic),
+		WithPanicHandler(func(any, []byte) { handled.Add(1) }),
+	)
+	list.PushBack(1)
+	h, _ = list.pop(nil)
+	err := list.cfg.invokeOnce(t.Context(), func(ctx context.Context) error {
+		list.do(ctx, h)
+		return nil
+	})
+	if !errors.As(err, &pe) || pe.Value != "boom" || h.Err() != err {
+		t.Errorf("expected panic error of the item; got %v", err)
+	}
+	if n := handled.Load(); n != 1 {
+		t.Errorf("expected 1 handled panic; got %d", n)
+	}
+}
+
+func TestJobListTimeout(t *testing.T) {
+	list := NewErrJobList(1, func(ctx context.Context, i int) error {
+		if i == 1 {
+			panic("boom")
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	}, WithTimeout(10*time.Millisecond), WithPanicPolicy(PanicReturn))
+	list.Start(t.Context())
+	h1, _ := list.PushBack(1)
+	h2, _ := list.PushBack(2)
+	var pe *PanicError
+	if err := h1.Wait(t.Context()); !errors.As(err, &pe) {
+		t.Errorf("expected panic error; got %v", err)
+	}
+	if err := h2.Wait(t.Context()); !errors.Is(err, ErrJobTimeout) {
+		t.Errorf("expected %v; got %v", ErrJobTimeout, err)
+	}
+	list.Shutdown(t.Context())
+	if n := list.Stats().Failed; n != 2 {
+		t.Errorf("expected 2 failed; got %d", n)
+	}
+}
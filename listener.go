@@ -0,0 +1,190 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var errStopped = errors.New("listener is stopped")
+
+// maxListenerErrors is the number of job errors kept by a Listener, so that
+// a listener running indefinitely does not grow without bound.
+const maxListenerErrors = 100
+
+// Listener is a handle of jobs listening started by Workers.Listen.
+type Listener struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	c      <-chan func()
+	cfg    *config
+
+	jobs      sync.WaitGroup
+	done      chan struct{}
+	drain     chan struct{}
+	drainOnce sync.Once
+
+	mu      sync.Mutex
+	errs    []error
+	omitted int // number of errors beyond maxListenerErrors
+}
+
+// newListener starts listening with n goroutines limited by w in persistent mode,
+// or a goroutine for each job limited by w otherwise. w may be nil in persistent mode.
+func newListener(ctx context.Context, c <-chan func(), w limiter, n int64, cfg *config) *Listener {
+	ctx, cancel := context.WithCancelCause(ctx)
+	l := &Listener{
+		ctx:    ctx,
+		cancel: cancel,
+		c:      c,
+		cfg:    cfg,
+		done:   make(chan struct{}),
+		drain:  make(chan struct{}),
+	}
+	if cfg.persistent {
+		var wg sync.WaitGroup
+		for range n {
+			wg.Go(func() {
+				for {
+					if w != nil {
+						if err := w.Acquire(l.ctx, 1); err != nil {
+							return
+						}
+					}
+					job, ok := l.receive()
+					ok = ok && cfg.waitRate(l.ctx) == nil
+					if ok {
+						l.exec(job)
+					}
+					if w != nil {
+						w.Release(1)
+					}
+					if !ok {
+						return
+					}
+				}
+			})
+		}
+		go func() {
+			wg.Wait()
+			close(l.done)
+		}()
+	} else {
+		go l.listen(w)
+	}
+	return l
+}
+
+// receive waits for the next job. It returns false if the listener should stop.
+// The rate limit is waited for once a job is received, so that idle goroutines
+// hold no token.
+func (l *Listener) receive() (func(), bool) {
+	select {
+	case <-l.ctx.Done():
+		return nil, false
+	case <-l.drain:
+		select {
+		case job, ok := <-l.c:
+			return job, ok
+		default:
+			return nil, false
+		}
+	case job, ok := <-l.c:
+		return job, ok
+	}
+}
+
+func (l *Listener) listen(w limiter) {
+	defer close(l.done)
+	for {
+		if err := w.Acquire(l.ctx, 1); err != nil {
+			return
+		}
+		job, ok := l.receive()
+		if !ok || l.cfg.waitRate(l.ctx) != nil {
+			w.Release(1)
+			return
+		}
+		l.jobs.Add(1)
+		go func() {
+			defer l.jobs.Done()
+			defer w.Release(1)
+			l.exec(job)
+		}()
+	}
+}
+
+func (l *Listener) exec(job func()) {
+	if job == nil {
+		return
+	}
+	if err := l.cfg.invoke(l.ctx, func(context.Context) error {
+		job()
+		return nil
+	}); err != nil {
+		if e, ok := err.(*PanicError); ok && l.cfg.panicPolicy == PanicRepanic {
+			panic(e)
+		}
+		l.mu.Lock()
+		if len(l.errs) < maxListenerErrors {
+			l.errs = append(l.errs, err)
+		} else {
+			l.omitted++
+		}
+		l.mu.Unlock()
+	}
+}
+
+// Stop stops receiving new jobs from the channel without cancelling the parent context.
+// Jobs already dispatched keep running; use Wait to wait for them.
+func (l *Listener) Stop() {
+	l.cancel(errStopped)
+}
+
+// Wait blocks until the listener stops receiving jobs and all dispatched jobs have returned.
+func (l *Listener) Wait() {
+	<-l.done
+	l.jobs.Wait()
+}
+
+// Drain dispatches the jobs already buffered in the channel, stops receiving new jobs
+// and waits for all dispatched jobs to return. If ctx is done first, the listener is
+// stopped and the context error is returned.
+func (l *Listener) Drain(ctx context.Context) error {
+	l.drainOnce.Do(func() { close(l.drain) })
+	done := make(chan struct{})
+	go func() {
+		l.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		l.Stop()
+		return ctx.Err()
+	}
+}
+
+// Err returns the context error if the listener stopped because the parent context is done,
+// joined with the errors of panicking jobs under PanicReturn and of jobs exceeding the timeout.
+// Only the first 100 job errors are kept, followed by the number of the omitted ones.
+// It returns nil if the listener was stopped by Stop or Drain, or the channel was closed.
+func (l *Listener) Err() error {
+	var err error
+	select {
+	case <-l.done:
+		if cause := context.Cause(l.ctx); cause != errStopped {
+			err = l.ctx.Err()
+		}
+	default:
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	errs := append([]error{err}, l.errs...)
+	if l.omitted > 0 {
+		errs = append(errs, fmt.Errorf("%d more job errors omitted", l.omitted))
+	}
+	return errors.Join(errs...)
+}
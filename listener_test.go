@@ -0,0 +1,88 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestListenerDrain(t *testing.T) {
+	var n atomic.Int64
+	c := make(chan func(), 10)
+	for range 10 {
+		c <- func() {
+			time.Sleep(10 * time.Millisecond)
+			n.Add(1)
+		}
+	}
+	l := Workers(2).Listen(context.Background(), c)
+	if err := l.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := n.Load(); n != 10 {
+		t.Errorf("expected 10; got %d", n)
+	}
+	if err := l.Err(); err != nil {
+		t.Errorf("expected nil; got %v", err)
+	}
+}
+
+func TestListenerStop(t *testing.T) {
+	var n atomic.Int64
+	c := make(chan func())
+	l := Workers(2).Listen(context.Background(), c)
+	c <- func() {
+		time.Sleep(50 * time.Millisecond)
+		n.Add(1)
+	}
+	l.Stop()
+	l.Wait()
+	if n := n.Load(); n != 1 {
+		t.Errorf("expected 1; got %d", n)
+	}
+	select {
+	case c <- func() {}:
+		t.Error("expected listener to be stopped")
+	case <-time.After(10 * time.Millisecond):
+	}
+	if err := l.Err(); err != nil {
+		t.Errorf("expected nil; got %v", err)
+	}
+}
+
+func TestListenerErr(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := make(chan func(), 1)
+	c <- func() { panic("boom") }
+	l := Workers(1).Listen(ctx, c, WithPanicPolicy(PanicReturn))
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	l.Wait()
+	err := l.Err()
+	var pe *PanicError
+	if !errors.Is(err, context.Canceled) || !errors.As(err, &pe) {
+		t.Errorf("expected cancel and panic errors; got %v", err)
+	}
+}
+
+func TestListenerErrLimit(t *testing.T) {
+	c := make(chan func(), maxListenerErrors+10)
+	for range maxListenerErrors + 10 {
+		c <- func() { panic("boom") }
+	}
+	close(c)
+	l := Workers(4).Listen(context.Background(), c, WithPanicPolicy(PanicReturn))
+	l.Wait()
+	l.mu.Lock()
+	n, omitted := len(l.errs), l.omitted
+	l.mu.Unlock()
+	if n != maxListenerErrors || omitted != 10 {
+		t.Errorf("expected %d errors and 10 omitted; got %d and %d", maxListenerErrors, n, omitted)
+	}
+	if err := l.Err(); err == nil || !strings.HasSuffix(err.Error(), "10 more job errors omitted") {
+		t.Errorf("expected omitted errors to be reported; got %v", err)
+	}
+}
@@ -0,0 +1,17 @@
+package workers
+
+import "context"
+
+// Map applies f to each element of in concurrently using r and returns
+// the results in the order of in. Errors are handled as in Workers.RunErr, with
+// the element index attached; if any error occurs, Map returns nil results.
+func Map[T, R any](ctx context.Context, r Runner, in []T, f func(T) (R, error), opts ...Option) ([]R, error) {
+	out := make([]R, len(in))
+	if err := r.RunErr(ctx, SliceErrJob(in, func(i int, v T) (err error) {
+		out[i], err = f(v)
+		return
+	}), opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
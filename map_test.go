@@ -0,0 +1,34 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestMapFunc(t *testing.T) {
+	res, err := Map(context.Background(), Workers(3), []int{3, 2, 1, 0}, func(n int) (string, error) {
+		time.Sleep(time.Duration(n) * 10 * time.Millisecond)
+		return strconv.Itoa(n), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := []string{"3", "2", "1", "0"}; !reflect.DeepEqual(expect, res) {
+		t.Errorf("expected %v; got %v", expect, res)
+	}
+
+	ints, err := Map(context.Background(), Workers(3), []string{"1", "a", "3"}, func(s string) (int, error) {
+		return strconv.Atoi(s)
+	})
+	var e *JobError
+	if !errors.As(err, &e) || e.Key != 1 {
+		t.Errorf("expected error of index 1; got %v", err)
+	}
+	if ints != nil {
+		t.Errorf("expected nil; got %v", ints)
+	}
+}
@@ -0,0 +1,70 @@
+package workers
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrJobTimeout is returned for a job running longer than the timeout set by WithTimeout.
+var ErrJobTimeout = errors.New("job timeout")
+
+// Option configures how Workers run jobs.
+type Option func(*config)
+
+type config struct {
+	failFast   bool
+	wait       bool
+	persistent bool
+	rate       *RateLimiter
+	timeout    time.Duration
+	retry      *RetryPolicy
+
+	aging    time.Duration
+	capacity int
+	overflow OverflowPolicy
+
+	deadLetter any // func(context.Context, DeadLetter[T]) of the job list's T
+
+	panicPolicy  PanicPolicy
+	panicHandler func(any, []byte)
+}
+
+func newConfig(opts []Option) *config {
+	c := new(config)
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithFailFast makes Run stop at the first job error: no more jobs are fetched,
+// the context derived for the run is cancelled and the first error is returned
+// after the jobs already started have finished.
+func WithFailFast() Option {
+	return func(c *config) { c.failFast = true }
+}
+
+// WithWait makes Run block until every started job has returned when the context
+// is cancelled, instead of returning immediately. No more jobs are started after
+// the cancellation, and Run reports the outcome as a *CancelError. Jobs abandoned
+// under WithTimeout are not waited for.
+func WithWait() Option {
+	return func(c *config) { c.wait = true }
+}
+
+// WithPersistent makes Run use a fixed set of long-lived goroutines, one per worker,
+// which pull jobs one after another instead of starting a goroutine for each job.
+// It reduces the overhead for a large number of small jobs.
+func WithPersistent() Option {
+	return func(c *config) { c.persistent = true }
+}
+
+// WithTimeout limits the running time of each job to d. The job gets a context
+// with the deadline, and once the deadline is exceeded it is abandoned: its slot
+// is released and an error wrapping ErrJobTimeout is reported, even if the job
+// function has not returned yet. The abandoned function keeps running in the
+// background until it returns, so the number of running functions can exceed
+// the limit of the workers; job functions should return once their context is done.
+func WithTimeout(d time.Duration) Option {
+	return func(c *config) { c.timeout = d }
+}
@@ -0,0 +1,75 @@
+package workers
+
+import (
+	"fmt"
+	"log"
+	"runtime/debug"
+)
+
+// PanicPolicy defines what happens when a job panics.
+type PanicPolicy int
+
+const (
+	// PanicLog logs the panic and its stack, then carries on. It is the default policy.
+	PanicLog PanicPolicy = iota
+	// PanicReturn converts the panic into a *PanicError returned from Run like any other job error,
+	// or from Listener.Err for jobs started by Listen.
+	PanicReturn
+	// PanicRepanic stops the run and re-panics with a *PanicError in the goroutine calling Run.
+	// Jobs started by Listen have no caller to report to, so they re-panic in place.
+	PanicRepanic
+)
+
+// PanicError records the value and stack of a recovered job panic.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string { return fmt.Sprintf("panic: %v\n%s", e.Value, e.Stack) }
+
+// Unwrap returns the panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// WithPanicPolicy sets the policy applied to panicking jobs.
+func WithPanicPolicy(p PanicPolicy) Option {
+	return func(c *config) { c.panicPolicy = p }
+}
+
+// WithPanicHandler sets a function called with the value and stack of every
+// recovered job panic. It replaces the logging done by PanicLog and is called
+// before the policy is applied.
+func WithPanicHandler(f func(v any, stack []byte)) Option {
+	return func(c *config) { c.panicHandler = f }
+}
+
+// handledPanic re-panics a *PanicError already handled by panicError, which the
+// recovering listener passes on as is.
+type handledPanic struct{ *PanicError }
+
+// recovered handles a value returned by recover and returns the resulting
+// *PanicError unless the panic is only to be logged.
+func (c *config) recovered(v any) *PanicError {
+	e := c.panicError(v)
+	if c.panicPolicy == PanicLog {
+		return nil
+	}
+	return e
+}
+
+// panicError handles a value returned by recover, calling the panic handler or
+// logging it under PanicLog, and returns it as a *PanicError.
+func (c *config) panicError(v any) *PanicError {
+	stack := debug.Stack()
+	if c.panicHandler != nil {
+		c.panicHandler(v, stack)
+	} else if c.panicPolicy == PanicLog {
+		log.Printf("panic: %v\n%s", v, stack)
+	}
+	return &PanicError{v, stack}
+}
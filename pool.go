@@ -0,0 +1,83 @@
+package workers
+
+import (
+	"context"
+	"slices"
+)
+
+// Runner is the interface implemented by Workers and *Pool.
+type Runner interface {
+	Run(context.Context, Job, ...Option) error
+	RunErr(context.Context, ErrJob, ...Option) error
+	RunContext(context.Context, ContextJob, ...Option) error
+	RunWeighted(context.Context, WeightedJob, ...Option) error
+	Listen(context.Context, <-chan func(), ...Option) *Listener
+}
+
+var (
+	_ Runner = Workers(0)
+	_ Runner = new(Pool)
+)
+
+// Pool is a worker pool with one limit shared by all its Run, Listen and JobList users,
+// so that the total number of running jobs never exceeds its size.
+// The size can be changed at runtime with Resize.
+type Pool struct {
+	sem  *resizableLimiter
+	opts []Option
+}
+
+// NewPool creates a new Pool of the given size, or GOMAXPROCS if size <= 0.
+// The options apply to every run of the pool, before the options of the run itself.
+func NewPool(size int, opts ...Option) *Pool {
+	return &Pool{sem: newResizableLimiter(Workers(size).weight()), opts: opts}
+}
+
+// Size returns the current size of the pool.
+func (p *Pool) Size() int {
+	return int(p.sem.Size())
+}
+
+// Resize changes the size of the pool, or sets it to GOMAXPROCS if size <= 0.
+// Growing admits waiting jobs immediately; shrinking lets running jobs finish
+// and holds new ones until the number of running jobs drops below the new size.
+// Runs and listeners in persistent mode keep the number of goroutines they
+// started with, so they cannot grow beyond it.
+func (p *Pool) Resize(size int) {
+	p.sem.Resize(Workers(size).weight())
+}
+
+func (p *Pool) config(opts []Option) *config {
+	return newConfig(append(slices.Clip(p.opts), opts...))
+}
+
+// Run executes jobs from the Job interface until there are no more jobs,
+// sharing the pool limit with other users.
+func (p *Pool) Run(ctx context.Context, job Job, opts ...Option) error {
+	return run(ctx, p.sem, p.sem.Size(), jobNext(job), false, p.config(opts))
+}
+
+// RunErr executes jobs from the ErrJob interface until there are no more jobs,
+// sharing the pool limit with other users. Errors are aggregated as in Workers.RunErr.
+func (p *Pool) RunErr(ctx context.Context, job ErrJob, opts ...Option) error {
+	return run(ctx, p.sem, p.sem.Size(), errJobNext(job), false, p.config(opts))
+}
+
+// RunContext executes jobs from the ContextJob interface until there are no more jobs,
+// sharing the pool limit with other users. Errors are aggregated as in Workers.RunErr.
+func (p *Pool) RunContext(ctx context.Context, job ContextJob, opts ...Option) error {
+	return run(ctx, p.sem, p.sem.Size(), contextJobNext(job), false, p.config(opts))
+}
+
+// RunWeighted executes jobs from the WeightedJob interface until there are no more jobs,
+// sharing the pool limit with other users. Each job acquires its weight from the pool,
+// the whole pool if the weight exceeds its size.
+func (p *Pool) RunWeighted(ctx context.Context, job WeightedJob, opts ...Option) error {
+	return run(ctx, p.sem, p.sem.Size(), job.Next, true, p.config(opts))
+}
+
+// Listen listens for jobs from a channel and runs them concurrently,
+// sharing the pool limit with other users.
+func (p *Pool) Listen(ctx context.Context, c <-chan func(), opts ...Option) *Listener {
+	return newListener(ctx, c, p.sem, p.sem.Size(), p.config(opts))
+}
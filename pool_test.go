@@ -0,0 +1,116 @@
+package workers
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPool(t *testing.T) {
+	p := NewPool(2)
+	var running, max atomic.Int64
+	job := func(int) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for m := max.Load(); n > m && !max.CompareAndSwap(m, n); m = max.Load() {
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Go(func() {
+			if err := p.Run(context.Background(), RangeJob(1, 4, job)); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	c := make(chan func(), 4)
+	for i := range 4 {
+		c <- func() { job(i) }
+	}
+	close(c)
+	l := p.Listen(context.Background(), c)
+	list := NewJobListWith(p, func(i int) {
+		defer wg.Done()
+		job(i)
+	})
+	list.Start(t.Context())
+	for i := range 4 {
+		wg.Add(1)
+		list.PushBack(i)
+	}
+	wg.Wait()
+	l.Wait()
+	if n := max.Load(); n != 2 {
+		t.Errorf("expected at most 2 running jobs; got %d", n)
+	}
+}
+
+func TestPoolResize(t *testing.T) {
+	p := NewPool(1)
+	var running atomic.Int64
+	release := make(chan struct{})
+	c := make(chan func(), 10)
+	for range 10 {
+		c <- func() {
+			running.Add(1)
+			defer running.Add(-1)
+			<-release
+		}
+	}
+	close(c)
+	l := p.Listen(context.Background(), c)
+	time.Sleep(20 * time.Millisecond)
+	if n := running.Load(); n != 1 {
+		t.Fatalf("expected 1 running job; got %d", n)
+	}
+	p.Resize(3)
+	time.Sleep(20 * time.Millisecond)
+	if n := running.Load(); n != 3 {
+		t.Fatalf("expected 3 running jobs; got %d", n)
+	}
+	p.Resize(1)
+	for range 3 {
+		release <- struct{}{}
+	}
+	time.Sleep(20 * time.Millisecond)
+	if n := running.Load(); n != 1 {
+		t.Fatalf("expected 1 running job; got %d", n)
+	}
+	if n := p.Size(); n != 1 {
+		t.Errorf("expected size 1; got %d", n)
+	}
+	close(release)
+	l.Wait()
+}
+
+func TestPoolShrinkWeighted(t *testing.T) {
+	p := NewPool(4)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var n atomic.Int64
+	job := SliceWeightedJob([]int64{1, 4}, func(w int64) int64 { return w }, func(_ context.Context, _ int, w int64) error {
+		if w == 1 {
+			close(started)
+			<-release
+		}
+		n.Add(w)
+		return nil
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	go func() {
+		<-started
+		p.Resize(2)
+		close(release)
+	}()
+	if err := p.RunWeighted(ctx, job); err != nil {
+		t.Fatal(err)
+	}
+	if n := n.Load(); n != 5 {
+		t.Errorf("expected 5; got %d", n)
+	}
+}
@@ -0,0 +1,125 @@
+package workers
+
+import (
+	"container/heap"
+	"context"
+	"slices"
+	"time"
+)
+
+// PriorityJobList is a job list whose items are processed by priority, higher first,
+// and in push order within the same priority.
+type PriorityJobList[T any] struct {
+	*jobList[T]
+	q *priorityQueue[T]
+}
+
+// NewPriorityJobList creates a new PriorityJobList with the given Runner and job function.
+// The options apply to the dispatch of the jobs as for JobList, and WithAging enables aging.
+func NewPriorityJobList[T any](r Runner, f func(context.Context, T) error, opts ...Option) *PriorityJobList[T] {
+	q := &priorityQueue[T]{aging: newConfig(opts).aging, start: time.Now()}
+	l := &PriorityJobList[T]{newJobList(q, r, f, opts), q}
+	l.deadLetter = deadLetter(opts, func(v T) T { return v }, false)
+	return l
+}
+
+// PushWithPriority adds a job with the given priority to the job list and signals the worker pool.
+func (l *PriorityJobList[T]) PushWithPriority(v T, priority int) error {
+	return l.PushWithPriorityContext(context.Background(), v, priority)
+}
+
+// PushWithPriorityContext is like PushWithPriority, but gives up when ctx is done while blocked by a full job list.
+func (l *PriorityJobList[T]) PushWithPriorityContext(ctx context.Context, v T, priority int) error {
+	return l.push(ctx, func() { l.q.push(v, priority) })
+}
+
+// WithAging raises the priority of the items waiting in a PriorityJobList by one
+// every d, so that low-priority items are not starved. It has no effect elsewhere.
+func WithAging(d time.Duration) Option {
+	return func(c *config) { c.aging = d }
+}
+
+type priorityItem[T any] struct {
+	v   T
+	key float64
+	seq uint64
+}
+
+func (a priorityItem[T]) before(b priorityItem[T]) bool {
+	return a.key > b.key || a.key == b.key && a.seq < b.seq
+}
+
+// priorityQueue is a heap of items ordered by key, then by push order.
+// With aging, the key of an item is its priority minus its push time in units
+// of aging: as all waiting items age at the same rate, the order of the keys
+// never changes and the heap stays valid.
+type priorityQueue[T any] struct {
+	h     []priorityItem[T]
+	seq   uint64
+	aging time.Duration
+	start time.Time
+}
+
+func (q *priorityQueue[T]) push(v T, priority int) {
+	key := float64(priority)
+	if q.aging > 0 {
+		key -= float64(time.Since(q.start)) / float64(q.aging)
+	}
+	q.seq++
+	heap.Push(q, priorityItem[T]{v, key, q.seq})
+}
+
+func (q *priorityQueue[T]) pop() (v T, ok bool) {
+	if len(q.h) == 0 {
+		return
+	}
+	return heap.Pop(q).(priorityItem[T]).v, true
+}
+
+func (q *priorityQueue[T]) dropOldest() (v T, ok bool) {
+	if len(q.h) == 0 {
+		return
+	}
+	oldest := 0
+	for i, item := range q.h {
+		if item.seq < q.h[oldest].seq {
+			oldest = i
+		}
+	}
+	return heap.Remove(q, oldest).(priorityItem[T]).v, true
+}
+
+func (q *priorityQueue[T]) items() []T {
+	items := slices.Clone(q.h)
+	slices.SortFunc(items, func(a, b priorityItem[T]) int {
+		if a.before(b) {
+			return -1
+		}
+		return 1
+	})
+	s := make([]T, len(items))
+	for i, item := range items {
+		s[i] = item.v
+	}
+	return s
+}
+
+func (q *priorityQueue[T]) clear() { q.h = nil }
+
+func (q *priorityQueue[T]) ready() int { return len(q.h) }
+
+func (q *priorityQueue[T]) Len() int { return len(q.h) }
+
+func (q *priorityQueue[T]) Less(i, j int) bool { return q.h[i].before(q.h[j]) }
+
+func (q *priorityQueue[T]) Swap(i, j int) { q.h[i], q.h[j] = q.h[j], q.h[i] }
+
+func (q *priorityQueue[T]) Push(x any) { q.h = append(q.h, x.(priorityItem[T])) }
+
+func (q *priorityQueue[T]) Pop() any {
+	n := len(q.h) - 1
+	item := q.h[n]
+	q.h[n] = priorityItem[T]{}
+	q.h = q.h[:n]
+	return item
+}
@@ -0,0 +1,59 @@
+package workers
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPriorityJobList(t *testing.T) {
+	var mu sync.Mutex
+	var res []string
+	var wg sync.WaitGroup
+	block := make(chan struct{})
+	list := NewPriorityJobList(Workers(1), func(_ context.Context, s string) error {
+		defer wg.Done()
+		if s == "block" {
+			<-block
+			return nil
+		}
+		mu.Lock()
+		res = append(res, s)
+		mu.Unlock()
+		return nil
+	})
+	list.Start(t.Context())
+	wg.Add(1)
+	list.PushWithPriority("block", 0)
+	time.Sleep(10 * time.Millisecond)
+	for _, i := range []struct {
+		s string
+		p int
+	}{{"low1", 0}, {"high1", 2}, {"mid", 1}, {"low2", 0}, {"high2", 2}} {
+		wg.Add(1)
+		list.PushWithPriority(i.s, i.p)
+	}
+	close(block)
+	wg.Wait()
+	if expect := []string{"high1", "high2", "mid", "low1", "low2"}; !reflect.DeepEqual(expect, res) {
+		t.Errorf("expected %v; got %v", expect, res)
+	}
+}
+
+func TestPriorityAging(t *testing.T) {
+	q := &priorityQueue[string]{aging: 10 * time.Millisecond, start: time.Now()}
+	q.push("old", 0)
+	time.Sleep(30 * time.Millisecond)
+	q.push("new", 2)
+	if v, _ := q.pop(); v != "old" {
+		t.Errorf("expected old; got %s", v)
+	}
+	if v, _ := q.pop(); v != "new" {
+		t.Errorf("expected new; got %s", v)
+	}
+	if _, ok := q.pop(); ok {
+		t.Error("expected empty queue")
+	}
+}
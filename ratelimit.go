@@ -0,0 +1,85 @@
+package workers
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket limiting how many jobs may start per second.
+// A RateLimiter may be shared by several runs, listeners and job lists to
+// enforce a common rate.
+type RateLimiter struct {
+	mu     sync.Mutex
+	limit  float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter creates a RateLimiter that allows limit jobs per second on average,
+// with bursts of at most burst jobs. A burst less than 1 is treated as 1.
+func NewRateLimiter(limit float64, burst int) *RateLimiter {
+	b := float64(max(burst, 1))
+	return &RateLimiter{limit: limit, burst: b, tokens: b, last: time.Now()}
+}
+
+// advance refills the bucket up to now. It must be called with mu held.
+func (l *RateLimiter) advance(now time.Time) {
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens = math.Min(l.burst, l.tokens+elapsed.Seconds()*l.limit)
+		l.last = now
+	}
+}
+
+// Wait blocks until a job is allowed to start or ctx is done.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	l.mu.Lock()
+	now := time.Now()
+	l.advance(now)
+	l.tokens--
+	if l.tokens >= 0 {
+		l.mu.Unlock()
+		return nil
+	}
+	if l.limit <= 0 {
+		l.tokens++
+		l.mu.Unlock()
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	d := time.Duration(-l.tokens / l.limit * float64(time.Second))
+	l.mu.Unlock()
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		// Give back the reserved token.
+		l.mu.Lock()
+		l.advance(time.Now())
+		l.tokens = math.Min(l.burst, l.tokens+1)
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// WithRateLimit makes jobs wait for l before they start, in addition to the
+// concurrency limit. Pass the same RateLimiter to share the rate between users.
+func WithRateLimit(l *RateLimiter) Option {
+	return func(c *config) { c.rate = l }
+}
+
+// waitRate waits for the rate limiter if there is one.
+func (c *config) waitRate(ctx context.Context) error {
+	if c.rate == nil {
+		return nil
+	}
+	return c.rate.Wait(ctx)
+}
@@ -0,0 +1,60 @@
+package workers
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	l := NewRateLimiter(20, 2)
+	start := time.Now()
+	if err := Workers(10).Run(context.Background(), RangeJob(1, 6, func(int) {}), WithRateLimit(l)); err != nil {
+		t.Fatal(err)
+	}
+	// 2 jobs in burst, then 4 jobs at 50ms intervals.
+	if d := time.Since(start); d < 180*time.Millisecond || d > time.Second {
+		t.Errorf("expected about 200ms; got %s", d)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+	list := NewJobList(3, func(int) { wg.Done() }, WithRateLimit(l))
+	list.Start(t.Context())
+	start = time.Now()
+	for i := range 3 {
+		list.PushBack(i)
+	}
+	wg.Wait()
+	if d := time.Since(start); d < 90*time.Millisecond {
+		t.Errorf("expected about 100ms; got %s", d)
+	}
+
+	// Idle listeners hold no token, so the burst is not exceeded once jobs come.
+	for _, opts := range [][]Option{nil, {WithPersistent()}} {
+		c := make(chan func())
+		Workers(4).Listen(t.Context(), c, append(opts, WithRateLimit(NewRateLimiter(10, 1)))...)
+		time.Sleep(50 * time.Millisecond)
+		wg.Add(3)
+		start = time.Now()
+		for range 3 {
+			c <- wg.Done
+		}
+		wg.Wait()
+		if d := time.Since(start); d < 180*time.Millisecond {
+			t.Errorf("expected about 200ms; got %s", d)
+		}
+		close(c)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	l = NewRateLimiter(0, 1)
+	if err := l.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expected %v; got %v", context.DeadlineExceeded, err)
+	}
+}
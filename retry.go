@@ -0,0 +1,87 @@
+package workers
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+type attemptKey struct{}
+
+// Attempt returns the attempt number, starting at 1, of the job running with ctx
+// under a RetryPolicy. It returns 0 if ctx does not belong to a retried job.
+func Attempt(ctx context.Context) int {
+	n, _ := ctx.Value(attemptKey{}).(int)
+	return n
+}
+
+// RetryPolicy defines how failed jobs are retried.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first one.
+	// Values less than 2 disable retries.
+	MaxAttempts int
+	// Backoff is the delay before the second attempt. Each following delay is
+	// multiplied by Multiplier, up to MaxBackoff if it is positive.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Multiplier defaults to 2 if it is less than 1.
+	Multiplier float64
+	// Jitter randomizes each delay by up to the given fraction, in [0, 1].
+	Jitter float64
+	// Retryable reports whether an error should be retried.
+	// If nil, all errors are retried.
+	Retryable func(error) bool
+}
+
+// delay returns the delay after the given failed attempt.
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	m := p.Multiplier
+	if m < 1 {
+		m = 2
+	}
+	d := float64(p.Backoff)
+	for range attempt - 1 {
+		d *= m
+		if p.MaxBackoff > 0 && d >= float64(p.MaxBackoff) {
+			break
+		}
+	}
+	if p.MaxBackoff > 0 {
+		d = min(d, float64(p.MaxBackoff))
+	}
+	if j := min(max(p.Jitter, 0), 1); j > 0 {
+		d *= 1 - j + 2*j*rand.Float64()
+	}
+	return time.Duration(d)
+}
+
+// Do calls f until it succeeds, the attempts are exhausted, the error is not
+// retryable or ctx is done. f receives a context carrying the attempt number,
+// see Attempt. Do returns the error of the last attempt.
+func (p RetryPolicy) Do(ctx context.Context, f func(context.Context) error) error {
+	return p.do(ctx, f, p.Retryable)
+}
+
+func (p RetryPolicy) do(ctx context.Context, f func(context.Context) error, retryable func(error) bool) error {
+	for attempt := 1; ; attempt++ {
+		err := f(context.WithValue(ctx, attemptKey{}, attempt))
+		if err == nil || attempt >= p.MaxAttempts || ctx.Err() != nil ||
+			retryable != nil && !retryable(err) {
+			return err
+		}
+		t := time.NewTimer(p.delay(attempt))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		case <-t.C:
+		}
+	}
+}
+
+// WithRetry retries failed jobs with the given policy. Each attempt is subject to
+// the timeout set by WithTimeout, and the job is reported as failed with the error
+// of its last attempt. Panicking jobs are retried under PanicReturn only.
+func WithRetry(p RetryPolicy) Option {
+	return func(c *config) { c.retry = &p }
+}
@@ -0,0 +1,71 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetry(t *testing.T) {
+	errTemp := errors.New("temporary")
+	errPerm := errors.New("permanent")
+	var attempts [3]atomic.Int64
+	err := Workers(3).RunContext(
+		context.Background(),
+		SliceContextJob([]int{0, 1, 2}, func(ctx context.Context, i int, _ int) error {
+			attempts[i].Store(int64(Attempt(ctx)))
+			switch i {
+			case 1:
+				if Attempt(ctx) < 3 {
+					return errTemp
+				}
+			case 2:
+				return errPerm
+			}
+			return nil
+		}),
+		WithRetry(RetryPolicy{
+			MaxAttempts: 5,
+			Backoff:     time.Millisecond,
+			Jitter:      0.5,
+			Retryable:   func(err error) bool { return !errors.Is(err, errPerm) },
+		}),
+	)
+	if !errors.Is(err, errPerm) || errors.Is(err, errTemp) {
+		t.Errorf("expected %v only; got %v", errPerm, err)
+	}
+	for i, expect := range []int64{1, 3, 1} {
+		if n := attempts[i].Load(); n != expect {
+			t.Errorf("expected %d attempts of job %d; got %d", expect, i, n)
+		}
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	var n atomic.Int64
+	list := NewErrJobList(1, func(ctx context.Context, _ int) error {
+		if n.Add(1) < 3 {
+			return errTemp
+		}
+		wg.Done()
+		return nil
+	}, WithRetry(RetryPolicy{MaxAttempts: 3}))
+	list.Start(t.Context())
+	list.PushBack(1)
+	wg.Wait()
+	if n := n.Load(); n != 3 {
+		t.Errorf("expected 3 attempts; got %d", n)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	p := RetryPolicy{Backoff: time.Second, MaxBackoff: 5 * time.Second, Multiplier: 3}
+	for i, expect := range []time.Duration{time.Second, 3 * time.Second, 5 * time.Second, 5 * time.Second} {
+		if d := p.delay(i + 1); d != expect {
+			t.Errorf("expected %s; got %s", expect, d)
+		}
+	}
+}
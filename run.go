@@ -0,0 +1,292 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// nextFunc returns the next job function with its weight, see Job and WeightedJob.
+type nextFunc func() (func(context.Context) error, int64, bool)
+
+// run executes jobs from next limited by l, which admits up to n jobs at once.
+// Unless weighted, a slot is acquired before fetching each job, so that no job
+// is fetched which cannot run.
+func run(ctx context.Context, l limiter, n int64, next nextFunc, weighted bool, cfg *config) error {
+	r := newRunner(ctx, cfg)
+	defer r.cancel()
+	if cfg.persistent {
+		r.spawn(l, n, next, weighted)
+	} else if err := r.dispatch(l, n, next, weighted); err != nil {
+		return r.result(err)
+	}
+	return r.result(r.wait())
+}
+
+// runner holds the state of a single Run call.
+type runner struct {
+	cfg    *config
+	ctx    context.Context // context passed to Run
+	runCtx context.Context // context passed to jobs
+	cancel context.CancelFunc
+
+	wg        sync.WaitGroup
+	completed atomic.Int64
+	abandoned atomic.Int64
+
+	mu       sync.Mutex
+	errs     []error
+	panicked *PanicError
+}
+
+func newRunner(ctx context.Context, cfg *config) *runner {
+	runCtx, cancel := context.WithCancel(ctx)
+	return &runner{cfg: cfg, ctx: ctx, runCtx: runCtx, cancel: cancel}
+}
+
+// dispatch starts a goroutine for each job, limited by w of size n. It returns
+// a non-nil error only if Run must return immediately.
+func (r *runner) dispatch(w limiter, n int64, next nextFunc, weighted bool) error {
+	for {
+		if err := r.cfg.waitRate(r.runCtx); err != nil {
+			return r.stopped()
+		}
+		if !weighted {
+			if err := w.Acquire(r.runCtx, 1); err != nil {
+				return r.stopped()
+			}
+		}
+		if r.runCtx.Err() != nil {
+			if !weighted {
+				w.Release(1)
+			}
+			return r.stopped()
+		}
+		f, weight, more := next()
+		if f == nil {
+			if !weighted {
+				w.Release(1)
+			}
+			return nil
+		}
+		weight = clampWeight(w, weight, n)
+		if weighted {
+			// The weight is only known once the job is fetched.
+			if err := w.Acquire(r.runCtx, weight); err != nil {
+				r.abandoned.Add(1)
+				return r.stopped()
+			}
+		}
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			defer w.Release(weight)
+			r.exec(f)
+		}()
+		if !more {
+			return nil
+		}
+	}
+}
+
+// stopped returns the error for Run to return immediately when dispatching is
+// interrupted, or nil if Run should wait for the started jobs.
+func (r *runner) stopped() error {
+	if err := r.ctx.Err(); err != nil && !r.cfg.wait {
+		return err
+	}
+	return nil
+}
+
+// clampWeight limits weight to [1, n] so that a job can always be admitted by w.
+// A resizableLimiter admits weights above its current size by itself, so they are
+// not limited by the size it had when the run started.
+func clampWeight(w limiter, weight, n int64) int64 {
+	if _, ok := w.(*resizableLimiter); ok {
+		return max(weight, 1)
+	}
+	return min(max(weight, 1), n)
+}
+
+// spawn starts n long-lived goroutines pulling jobs from next until there are no more jobs.
+// Each job is limited by w if it is not nil.
+func (r *runner) spawn(w limiter, n int64, next nextFunc, weighted bool) {
+	var mu sync.Mutex
+	var exhausted bool
+	fetch := func() (func(context.Context) error, int64) {
+		mu.Lock()
+		defer mu.Unlock()
+		if exhausted || r.runCtx.Err() != nil {
+			return nil, 0
+		}
+		f, weight, more := next()
+		if f == nil || !more {
+			exhausted = true
+		}
+		return f, clampWeight(w, weight, n)
+	}
+	for range n {
+		r.wg.Go(func() {
+			for {
+				if err := r.cfg.waitRate(r.runCtx); err != nil {
+					return
+				}
+				if w != nil && !weighted {
+					if err := w.Acquire(r.runCtx, 1); err != nil {
+						return
+					}
+				}
+				f, weight := fetch()
+				if f == nil {
+					if w != nil && !weighted {
+						w.Release(1)
+					}
+					return
+				}
+				if w != nil && weighted {
+					if err := w.Acquire(r.runCtx, weight); err != nil {
+						r.abandoned.Add(1)
+						return
+					}
+				}
+				r.exec(f)
+				if w != nil {
+					w.Release(weight)
+				}
+			}
+		})
+	}
+}
+
+// exec runs a single job, handling its error and panic.
+func (r *runner) exec(f func(context.Context) error) {
+	err := r.cfg.invoke(r.runCtx, f)
+	if err == nil {
+		r.completed.Add(1)
+		return
+	}
+	if e, ok := err.(*PanicError); ok && r.cfg.panicPolicy == PanicRepanic {
+		r.mu.Lock()
+		if r.panicked == nil {
+			r.panicked = e
+		}
+		r.mu.Unlock()
+		r.cancel()
+		return
+	}
+	if errors.Is(err, ErrJobTimeout) || r.ctx.Err() != nil && errors.Is(err, r.ctx.Err()) {
+		r.abandoned.Add(1)
+	} else {
+		r.completed.Add(1)
+	}
+	r.mu.Lock()
+	r.errs = append(r.errs, err)
+	r.mu.Unlock()
+	if r.cfg.failFast {
+		r.cancel()
+	}
+}
+
+// invoke calls f, retrying it as configured.
+func (c *config) invoke(ctx context.Context, f func(context.Context) error) error {
+	return c.retried(ctx, func(ctx context.Context) error { return c.invokeOnce(ctx, f) })
+}
+
+// retried calls f, retrying it as configured. Panics are not retried under PanicRepanic.
+func (c *config) retried(ctx context.Context, f func(context.Context) error) error {
+	if c.retry == nil {
+		return f(ctx)
+	}
+	return c.retry.do(ctx, f, func(err error) bool {
+		if _, ok := err.(*PanicError); ok && c.panicPolicy == PanicRepanic {
+			return false
+		}
+		return c.retry.Retryable == nil || c.retry.Retryable(err)
+	})
+}
+
+// invokeOnce calls f, converting its panic into a *PanicError as configured,
+// within the timeout if any, see timed.
+func (c *config) invokeOnce(ctx context.Context, f func(context.Context) error) error {
+	return c.timed(ctx, func(ctx context.Context) (err error) {
+		defer func() {
+			if v := recover(); v != nil {
+				if h, ok := v.(handledPanic); ok {
+					err = h.PanicError
+				} else if e := c.recovered(v); e != nil {
+					err = e
+				}
+			}
+		}()
+		return f(ctx)
+	})
+}
+
+// timed calls f. With a timeout, f gets a context with a deadline and is abandoned
+// once the deadline is exceeded: timed returns ErrJobTimeout, whatever f returns,
+// while f keeps running in the background. f must not panic.
+func (c *config) timed(ctx context.Context, f func(context.Context) error) error {
+	if c.timeout <= 0 {
+		return f(ctx)
+	}
+	jobCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- f(jobCtx) }()
+	// The job timed out unless ctx is done itself, e.g. by an earlier deadline.
+	timedOut := func() bool {
+		return ctx.Err() == nil && errors.Is(jobCtx.Err(), context.DeadlineExceeded)
+	}
+	select {
+	case err := <-done:
+		if !timedOut() {
+			return err
+		}
+	case <-jobCtx.Done():
+		if !timedOut() {
+			// ctx is done: f is waited for as without a timeout.
+			return <-done
+		}
+	}
+	return fmt.Errorf("%w after %s", ErrJobTimeout, c.timeout)
+}
+
+// wait waits for the started jobs as configured and returns the error of the run itself.
+func (r *runner) wait() error {
+	if r.cfg.wait {
+		r.wg.Wait()
+		if err := r.ctx.Err(); err != nil {
+			return &CancelError{Err: err, Completed: int(r.completed.Load()), Abandoned: int(r.abandoned.Load())}
+		}
+		return nil
+	}
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-r.ctx.Done():
+		return r.ctx.Err()
+	}
+}
+
+// result combines err with the errors of the jobs.
+// It re-panics if a job panicked under PanicRepanic.
+func (r *runner) result(err error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.panicked != nil {
+		panic(r.panicked)
+	}
+	if r.cfg.failFast && len(r.errs) > 0 {
+		return r.errs[0]
+	} else if len(r.errs) > 0 {
+		return errors.Join(append(r.errs, err)...)
+	}
+	return err
+}
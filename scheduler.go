@@ -0,0 +1,241 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// OverlapPolicy defines what happens when a scheduled job is due while its
+// previous run has not finished yet.
+type OverlapPolicy int
+
+const (
+	// OverlapSkip skips the run.
+	OverlapSkip OverlapPolicy = iota
+	// OverlapQueue delays the run until the previous ones have finished.
+	OverlapQueue
+	// OverlapAllow runs the job concurrently with its previous runs.
+	OverlapAllow
+)
+
+// Scheduler runs jobs on schedules, such as cron expressions or fixed intervals,
+// under the limit of a Runner. Due jobs are pushed to a JobList, so the options
+// given to NewScheduler apply as for the job list; under WithCapacity with
+// OverflowBlock, the scheduler waits for room before pushing more runs. A job
+// can push to another JobList to dispatch its work there.
+type Scheduler struct {
+	mu      sync.Mutex
+	r       Runner
+	opts    []Option
+	entries map[*Entry]struct{}
+	list    *JobList[*Entry]
+	due     []*Entry // runs admitted by their overlap policy, to be pushed
+	wake    chan struct{}
+	cancel  context.CancelFunc // stops the loop and its pushes
+	stopped chan struct{}
+}
+
+// Entry is a job added to a Scheduler.
+type Entry struct {
+	s        *Scheduler
+	schedule Schedule
+	overlap  OverlapPolicy
+	f        func(context.Context)
+
+	// guarded by the scheduler lock
+	next   time.Time
+	active int // number of runs admitted and not finished
+	queued int // number of runs delayed under OverlapQueue
+}
+
+// NewScheduler creates a new Scheduler running the jobs with r.
+func NewScheduler(r Runner, opts ...Option) *Scheduler {
+	return &Scheduler{r: r, opts: opts, entries: make(map[*Entry]struct{}), wake: make(chan struct{}, 1)}
+}
+
+// Add adds a job run on schedule with the given overlap policy.
+// The job receives the context passed to Start.
+func (s *Scheduler) Add(schedule Schedule, overlap OverlapPolicy, f func(context.Context)) *Entry {
+	e := &Entry{s: s, schedule: schedule, overlap: overlap, f: f}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[e] = struct{}{}
+	if s.list != nil {
+		e.next = schedule.Next(time.Now())
+		s.notify()
+	}
+	return e
+}
+
+// AddCron is like Add with a schedule parsed by ParseCron.
+func (s *Scheduler) AddCron(spec string, overlap OverlapPolicy, f func(context.Context)) (*Entry, error) {
+	schedule, err := ParseCron(spec)
+	if err != nil {
+		return nil, err
+	}
+	return s.Add(schedule, overlap, f), nil
+}
+
+// Remove removes the job from the scheduler. Its running and queued runs are not affected.
+func (e *Entry) Remove() {
+	s := e.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, e)
+	s.notify()
+}
+
+// Next returns the next activation time of the job, or the zero time if the
+// scheduler is not started.
+func (e *Entry) Next() time.Time {
+	e.s.mu.Lock()
+	defer e.s.mu.Unlock()
+	return e.next
+}
+
+// Start begins running the jobs on their schedules until ctx is done or Stop is called.
+// A scheduler stopped by Stop can be started again; after ctx is done, Stop must be
+// called before.
+func (s *Scheduler) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.list != nil {
+		return errors.New("scheduler is already started")
+	}
+	var list *JobList[*Entry]
+	list = NewJobListWith(s.r, func(e *Entry) { s.run(ctx, list, e) }, s.opts...)
+	if err := list.Start(ctx); err != nil {
+		return err
+	}
+	now := time.Now()
+	for e := range s.entries {
+		e.next = e.schedule.Next(now)
+	}
+	s.list = list
+	loopCtx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+	s.stopped = make(chan struct{})
+	go s.loop(loopCtx, list, s.stopped)
+	return nil
+}
+
+// Stop stops scheduling jobs, then waits for the runs already due to finish.
+// If ctx is done first, the pending runs are dropped and the context error is returned.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if s.list == nil {
+		s.mu.Unlock()
+		return errors.New("scheduler is not started")
+	}
+	list, stopped := s.list, s.stopped
+	s.list = nil
+	s.cancel()
+	for _, e := range s.due {
+		e.active--
+	}
+	s.due = nil
+	for e := range s.entries {
+		e.next = time.Time{}
+	}
+	s.mu.Unlock()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+	}
+	_, err := list.Shutdown(ctx)
+	return err
+}
+
+// loop pushes the due runs to list until ctx is done, which happens on Stop too.
+func (s *Scheduler) loop(ctx context.Context, list *JobList[*Entry], stopped chan struct{}) {
+	defer close(stopped)
+	t := time.NewTimer(0)
+	defer t.Stop()
+	for {
+		s.mu.Lock()
+		var next time.Time
+		for e := range s.entries {
+			if !e.next.IsZero() && (next.IsZero() || e.next.Before(next)) {
+				next = e.next
+			}
+		}
+		s.mu.Unlock()
+		if next.IsZero() {
+			t.Stop()
+		} else {
+			t.Reset(time.Until(next))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-t.C:
+		}
+		s.mu.Lock()
+		now := time.Now()
+		for e := range s.entries {
+			if !e.next.IsZero() && !e.next.After(now) {
+				s.admit(e)
+				e.next = e.schedule.Next(now)
+			}
+		}
+		due := s.due
+		s.due = nil
+		s.mu.Unlock()
+		// Pushing may block under OverflowBlock, so it is done without the lock,
+		// letting the running jobs finish, and gives up on Stop.
+		for _, e := range due {
+			if _, err := list.PushBackContext(ctx, e); err != nil {
+				s.mu.Lock()
+				e.active--
+				e.queued = 0
+				s.mu.Unlock()
+			}
+		}
+	}
+}
+
+// admit adds a run of e to the due runs as allowed by its overlap policy.
+// It must be called with the lock held.
+func (s *Scheduler) admit(e *Entry) {
+	if e.active > 0 {
+		switch e.overlap {
+		case OverlapSkip:
+			return
+		case OverlapQueue:
+			e.queued++
+			return
+		}
+	}
+	e.active++
+	s.due = append(s.due, e)
+}
+
+func (s *Scheduler) run(ctx context.Context, list *JobList[*Entry], e *Entry) {
+	defer func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		e.active--
+		if s.list != list {
+			// Stopped, the queued runs are dropped.
+			e.queued = 0
+		}
+		if e.queued > 0 && e.active == 0 {
+			e.queued--
+			e.active++
+			s.due = append(s.due, e)
+			s.notify()
+		}
+	}()
+	e.f(ctx)
+}
+
+// notify wakes up the loop to reconsider the entries. It must be called with the lock held.
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,121 @@
+package workers
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestScheduler(t *testing.T) {
+	s := NewScheduler(Workers(6))
+	var runs [3]atomic.Int64
+	var running atomic.Int64
+	var peak atomic.Int64
+	for i, overlap := range []OverlapPolicy{OverlapSkip, OverlapQueue, OverlapAllow} {
+		s.Add(Every(10*time.Millisecond), overlap, func(context.Context) {
+			runs[i].Add(1)
+			if i == 2 {
+				n := running.Add(1)
+				defer running.Add(-1)
+				if n > peak.Load() {
+					peak.Store(n)
+				}
+			}
+			time.Sleep(25 * time.Millisecond)
+		})
+	}
+	if err := s.Start(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(105 * time.Millisecond)
+	if err := s.Stop(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	skip, queue, allow := runs[0].Load(), runs[1].Load(), runs[2].Load()
+	if skip < 2 || skip > 4 {
+		t.Errorf("expected 2-4 skipping runs; got %d", skip)
+	}
+	if queue < skip || queue > 5 {
+		t.Errorf("expected %d-5 queuing runs; got %d", skip, queue)
+	}
+	if allow < 6 {
+		t.Errorf("expected at least 6 concurrent runs; got %d", allow)
+	}
+	if n := peak.Load(); n < 2 {
+		t.Errorf("expected concurrent runs; got %d at most", n)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if n := runs[2].Load(); n != allow {
+		t.Errorf("expected no run after stop; got %d more", n-allow)
+	}
+}
+
+func TestSchedulerBlock(t *testing.T) {
+	s := NewScheduler(Workers(1), WithCapacity(1, OverflowBlock))
+	var runs atomic.Int64
+	job := func(context.Context) {
+		runs.Add(1)
+		time.Sleep(5 * time.Millisecond)
+	}
+	s.Add(Every(time.Millisecond), OverlapAllow, job)
+	s.Start(t.Context())
+	time.Sleep(20 * time.Millisecond)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.Add(Every(time.Millisecond), OverlapAllow, job)
+		s.Stop(t.Context())
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("scheduler is blocked")
+	}
+	if runs.Load() == 0 {
+		t.Error("expected runs; got none")
+	}
+}
+
+func TestSchedulerRestart(t *testing.T) {
+	s := NewScheduler(Workers(1))
+	var runs atomic.Int64
+	s.Add(Every(5*time.Millisecond), OverlapSkip, func(context.Context) { runs.Add(1) })
+	for range 2 {
+		before := runs.Load()
+		if err := s.Start(t.Context()); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(30 * time.Millisecond)
+		if err := s.Stop(t.Context()); err != nil {
+			t.Fatal(err)
+		}
+		if runs.Load() == before {
+			t.Error("expected runs after start; got none")
+		}
+	}
+	if err := s.Stop(t.Context()); err == nil {
+		t.Error("expected error stopping a stopped scheduler; got nil")
+	}
+}
+
+func TestSchedulerStopTimeout(t *testing.T) {
+	s := NewScheduler(Workers(1), WithCapacity(1, OverflowBlock))
+	hung := make(chan struct{})
+	defer close(hung)
+	s.Add(Every(time.Millisecond), OverlapAllow, func(context.Context) { <-hung })
+	s.Start(t.Context())
+	time.Sleep(20 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- s.Stop(ctx) }()
+	select {
+	case err := <-done:
+		if err != context.DeadlineExceeded {
+			t.Errorf("expected %v; got %v", context.DeadlineExceeded, err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Stop is blocked")
+	}
+}
@@ -2,9 +2,8 @@ package workers
 
 import (
 	"context"
-	"log"
+	"fmt"
 	"runtime"
-	"runtime/debug"
 
 	"golang.org/x/sync/semaphore"
 )
@@ -12,7 +11,9 @@ import (
 // DefaultWorkers is a default instance of Workers with the size of GOMAXPROCS.
 var DefaultWorkers = Workers(runtime.GOMAXPROCS(0))
 
-// Workers holds the size and semaphore for managing concurrent jobs.
+// Workers holds the size for managing concurrent jobs.
+// Each Run or Listen call has its own limit of that size; use Pool to share one
+// limit between calls.
 type Workers int
 
 func (i Workers) weight() int64 {
@@ -22,82 +23,107 @@ func (i Workers) weight() int64 {
 	return int64(i)
 }
 
+// CancelError is returned by Run with WithWait when the context is cancelled.
+// All started jobs have returned by the time it is reported, except the jobs
+// abandoned after exceeding the timeout set by WithTimeout, which may still be
+// running in the background.
+type CancelError struct {
+	// Err is the error of the cancelled context.
+	Err error
+	// Completed is the number of started jobs that ran to the end.
+	Completed int
+	// Abandoned is the number of started jobs that gave up because of the
+	// cancellation, i.e. returned an error matching Err, and of weighted jobs
+	// fetched but cancelled before their weight could be acquired.
+	Abandoned int
+}
+
+func (e *CancelError) Error() string {
+	return fmt.Sprintf("%v: %d completed, %d abandoned", e.Err, e.Completed, e.Abandoned)
+}
+
+// Unwrap returns the error of the cancelled context.
+func (e *CancelError) Unwrap() error { return e.Err }
+
 // Run executes jobs from the Job interface until there are no more jobs.
 // It acquires a semaphore weight for each job and releases it when the job is done.
-func (i Workers) Run(ctx context.Context, job Job) (err error) {
-	weight := i.weight()
-	w := semaphore.NewWeighted(weight)
-	for {
-		if err = w.Acquire(ctx, 1); err != nil {
-			return
-		}
-		f, next := job.Next()
-		if f == nil {
-			w.Release(1)
-			break
-		}
-		go func() {
-			defer w.Release(1)
-			defer func() {
-				if err := recover(); err != nil {
-					log.Printf("panic: %v\n%s", err, debug.Stack())
-				}
-			}()
-			f()
-		}()
-		if !next {
-			break
-		}
-	}
-	if err = w.Acquire(ctx, weight); err != nil {
-		return
+// Options such as WithFailFast change how jobs are run.
+func (i Workers) Run(ctx context.Context, job Job, opts ...Option) error {
+	return i.run(ctx, jobNext(job), opts)
+}
+
+// RunErr executes jobs from the ErrJob interface until there are no more jobs.
+// It returns all errors returned by the job functions joined by errors.Join.
+func (i Workers) RunErr(ctx context.Context, job ErrJob, opts ...Option) error {
+	return i.run(ctx, errJobNext(job), opts)
+}
+
+// RunContext executes jobs from the ContextJob interface until there are no more jobs.
+// Each job function receives a context derived from ctx, which is cancelled when ctx is
+// cancelled or when Run stops early. Errors are aggregated as in RunErr.
+func (i Workers) RunContext(ctx context.Context, job ContextJob, opts ...Option) error {
+	return i.run(ctx, contextJobNext(job), opts)
+}
+
+// RunWeighted executes jobs from the WeightedJob interface until there are no more jobs.
+// Each job acquires its weight from the limit of the workers, so the size of the
+// workers expresses a resource budget. As the weight is only known once a job is
+// fetched, a job may be fetched and then abandoned if the run stops while it waits
+// for its weight. Errors are aggregated as in RunErr.
+func (i Workers) RunWeighted(ctx context.Context, job WeightedJob, opts ...Option) error {
+	return i.runWeighted(ctx, job.Next, true, opts)
+}
+
+func (i Workers) run(ctx context.Context, next nextFunc, opts []Option) error {
+	return i.runWeighted(ctx, next, false, opts)
+}
+
+func (i Workers) runWeighted(ctx context.Context, next nextFunc, weighted bool, opts []Option) error {
+	cfg := newConfig(opts)
+	if cfg.persistent && !weighted {
+		// The number of goroutines already enforces the limit.
+		return run(ctx, nil, i.weight(), next, weighted, cfg)
 	}
-	w.Release(weight)
-	return
+	return run(ctx, semaphore.NewWeighted(i.weight()), i.weight(), next, weighted, cfg)
 }
 
 // Listen listens for jobs from a channel and runs them concurrently.
 // It stops listening when the context is done or the channel is closed.
-func (i Workers) Listen(ctx context.Context, c <-chan func()) {
-	w := semaphore.NewWeighted(i.weight())
-	go func() {
-		for {
-			if err := w.Acquire(ctx, 1); err != nil {
-				return
-			}
-			select {
-			case <-ctx.Done():
-				return
-			case job, ok := <-c:
-				if !ok {
-					return
-				}
-				if job != nil {
-					go func() {
-						defer w.Release(1)
-						defer func() {
-							if err := recover(); err != nil {
-								log.Printf("panic: %v\n%s", err, debug.Stack())
-							}
-						}()
-						job()
-					}()
-				} else {
-					w.Release(1)
-				}
-			}
-		}
-	}()
+// The returned Listener can be used to stop listening and wait for dispatched jobs.
+func (i Workers) Listen(ctx context.Context, c <-chan func(), opts ...Option) *Listener {
+	cfg := newConfig(opts)
+	if cfg.persistent {
+		return newListener(ctx, c, nil, i.weight(), cfg)
+	}
+	return newListener(ctx, c, semaphore.NewWeighted(i.weight()), i.weight(), cfg)
 }
 
 // Run executes jobs using DefaultWorkers from the Job interface until there are no more jobs.
 // It acquires a semaphore weight for each job and releases it when the job is done.
-func Run(ctx context.Context, job Job) error {
-	return DefaultWorkers.Run(ctx, job)
+func Run(ctx context.Context, job Job, opts ...Option) error {
+	return DefaultWorkers.Run(ctx, job, opts...)
+}
+
+// RunErr executes jobs using DefaultWorkers from the ErrJob interface until there are no more jobs.
+// It returns all errors returned by the job functions joined by errors.Join.
+func RunErr(ctx context.Context, job ErrJob, opts ...Option) error {
+	return DefaultWorkers.RunErr(ctx, job, opts...)
+}
+
+// RunContext executes jobs using DefaultWorkers from the ContextJob interface until there are no more jobs.
+// Each job function receives a context derived from ctx. Errors are aggregated as in RunErr.
+func RunContext(ctx context.Context, job ContextJob, opts ...Option) error {
+	return DefaultWorkers.RunContext(ctx, job, opts...)
+}
+
+// RunWeighted executes jobs using DefaultWorkers from the WeightedJob interface until there are no more jobs.
+// Each job acquires its weight from the limit of the workers. Errors are aggregated as in RunErr.
+func RunWeighted(ctx context.Context, job WeightedJob, opts ...Option) error {
+	return DefaultWorkers.RunWeighted(ctx, job, opts...)
 }
 
 // Listen listens for jobs using DefaultWorkers from a channel and runs them concurrently.
 // It stops listening when the context is done or the channel is closed.
-func Listen(ctx context.Context, c <-chan func()) {
-	DefaultWorkers.Listen(ctx, c)
+func Listen(ctx context.Context, c <-chan func(), opts ...Option) *Listener {
+	return DefaultWorkers.Listen(ctx, c, opts...)
 }
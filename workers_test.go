@@ -2,7 +2,10 @@ package workers
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"reflect"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -132,3 +135,305 @@ func TestListen(t *testing.T) {
 		t.Errorf("expected %v; got %v", expect, result)
 	}
 }
+
+func TestRunErr(t *testing.T) {
+	errOdd := errors.New("odd")
+	err := DefaultWorkers.RunErr(
+		context.Background(),
+		SliceErrJob(
+			[]int{1, 2, 3, 4},
+			func(_ int, n int) error {
+				if n%2 == 1 {
+					return errOdd
+				}
+				return nil
+			},
+		),
+	)
+	if !errors.Is(err, errOdd) {
+		t.Fatalf("expected %v; got %v", errOdd, err)
+	}
+	var keys []int
+	for _, err := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var e *JobError
+		if !errors.As(err, &e) {
+			t.Fatalf("expected JobError; got %v", err)
+		}
+		keys = append(keys, e.Key.(int))
+	}
+	slices.Sort(keys)
+	if expect := []int{0, 2}; !reflect.DeepEqual(expect, keys) {
+		t.Errorf("expected %v; got %v", expect, keys)
+	}
+
+	if err := DefaultWorkers.RunErr(
+		context.Background(),
+		MapErrJob(map[string]int{"a": 1}, func(string, int) error { return nil }),
+	); err != nil {
+		t.Errorf("expected nil; got %v", err)
+	}
+
+	err = DefaultWorkers.RunErr(
+		context.Background(),
+		RangeErrJob(3, 1, func(n int) error { return fmt.Errorf("%d", n) }),
+	)
+	if expect := 3; len(err.(interface{ Unwrap() []error }).Unwrap()) != expect {
+		t.Errorf("expected %d errors; got %v", expect, err)
+	}
+}
+
+func TestFailFast(t *testing.T) {
+	errFail := errors.New("fail")
+	var n atomic.Int64
+	err := Workers(2).RunErr(
+		context.Background(),
+		RangeErrJob(1, 100, func(i int) error {
+			n.Add(1)
+			if i == 3 {
+				return errFail
+			}
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		}),
+		WithFailFast(),
+	)
+	var e *JobError
+	if !errors.As(err, &e) || e.Err != errFail || e.Key != 3 {
+		t.Fatalf("expected job 3 error; got %v", err)
+	}
+	if n := n.Load(); n >= 100 {
+		t.Errorf("expected fewer than 100 jobs; got %d", n)
+	}
+}
+
+type countJob struct {
+	n atomic.Int64
+	f func() error
+}
+
+func (j *countJob) Next() (func() error, bool) {
+	j.n.Add(1)
+	return j.f, true
+}
+
+func TestFailFastNext(t *testing.T) {
+	job := &countJob{f: func() error { return errors.New("fail") }}
+	if err := Workers(1).RunErr(context.Background(), job, WithFailFast()); err == nil {
+		t.Fatal("expected error; got nil")
+	}
+	if n := job.n.Load(); n != 1 {
+		t.Errorf("expected 1 Next call; got %d", n)
+	}
+}
+
+func TestRunContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	var n atomic.Int64
+	start := time.Now()
+	if err := Workers(3).RunContext(
+		ctx,
+		SliceContextJob([]int{1, 2, 3}, func(ctx context.Context, _ int, _ int) error {
+			select {
+			case <-ctx.Done():
+				n.Add(1)
+				return ctx.Err()
+			case <-time.After(time.Minute):
+				return nil
+			}
+		}),
+	); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected %v; got %v", context.DeadlineExceeded, err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("expected cancellation to reach jobs; took %s", d)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if n := n.Load(); n != 3 {
+		t.Errorf("expected 3 cancelled jobs; got %d", n)
+	}
+}
+
+func TestPanic(t *testing.T) {
+	job := func() Job {
+		return SliceJob([]int{1, 2, 3}, func(_ int, n int) {
+			if n == 2 {
+				panic("boom")
+			}
+		})
+	}
+
+	var handled atomic.Int64
+	if err := DefaultWorkers.Run(
+		context.Background(),
+		job(),
+		WithPanicHandler(func(v any, stack []byte) {
+			if v == "boom" && len(stack) > 0 {
+				handled.Add(1)
+			}
+		}),
+	); err != nil {
+		t.Fatal(err)
+	}
+	if n := handled.Load(); n != 1 {
+		t.Errorf("expected 1 handled panic; got %d", n)
+	}
+
+	err := DefaultWorkers.Run(context.Background(), job(), WithPanicPolicy(PanicReturn))
+	var pe *PanicError
+	if !errors.As(err, &pe) || pe.Value != "boom" {
+		t.Errorf("expected PanicError; got %v", err)
+	}
+
+	defer func() {
+		if pe, ok := recover().(*PanicError); !ok || pe.Value != "boom" {
+			t.Errorf("expected PanicError panic; got %v", pe)
+		}
+	}()
+	DefaultWorkers.Run(context.Background(), job(), WithPanicPolicy(PanicRepanic))
+	t.Error("expected panic")
+}
+
+func TestWait(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var running atomic.Int64
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+	err := Workers(4).RunContext(
+		ctx,
+		RangeContextJob(0, 99, func(ctx context.Context, n int) error {
+			running.Add(1)
+			defer running.Add(-1)
+			if n%2 == 0 {
+				<-ctx.Done()
+				time.Sleep(10 * time.Millisecond)
+				return ctx.Err()
+			}
+			time.Sleep(100 * time.Millisecond)
+			return nil
+		}),
+		WithWait(),
+	)
+	if n := running.Load(); n != 0 {
+		t.Errorf("expected no running jobs; got %d", n)
+	}
+	var e *CancelError
+	if !errors.As(err, &e) {
+		t.Fatalf("expected CancelError; got %v", err)
+	}
+	if e.Completed+e.Abandoned != 4 || e.Abandoned != 2 {
+		t.Errorf("expected 2 completed and 2 abandoned; got %v", e)
+	}
+}
+
+func TestPersistent(t *testing.T) {
+	var n atomic.Int64
+	if err := Workers(4).Run(
+		context.Background(),
+		RangeJob(1, 1000, func(i int) { n.Add(int64(i)) }),
+		WithPersistent(),
+	); err != nil {
+		t.Fatal(err)
+	}
+	if expect, n := int64(500500), n.Load(); n != expect {
+		t.Errorf("expected %d; got %d", expect, n)
+	}
+
+	c := make(chan func(), 3)
+	for range 3 {
+		c <- func() { n.Add(-1) }
+	}
+	close(c)
+	Workers(2).Listen(context.Background(), c, WithPersistent()).Wait()
+	if expect, n := int64(500497), n.Load(); n != expect {
+		t.Errorf("expected %d; got %d", expect, n)
+	}
+}
+
+func benchmarkRun(b *testing.B, opts ...Option) {
+	var n atomic.Int64
+	for b.Loop() {
+		if err := DefaultWorkers.Run(
+			context.Background(),
+			RangeJob(1, 10000, func(i int) { n.Add(int64(i)) }),
+			opts...,
+		); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRun(b *testing.B) { benchmarkRun(b) }
+
+func BenchmarkRunPersistent(b *testing.B) { benchmarkRun(b, WithPersistent()) }
+
+func TestTimeout(t *testing.T) {
+	hung := make(chan struct{})
+	defer close(hung)
+	var n atomic.Int64
+	err := Workers(1).RunErr(
+		context.Background(),
+		SliceErrJob([]int{1, 2, 3}, func(_ int, i int) error {
+			if i == 1 {
+				<-hung
+			}
+			n.Add(1)
+			return nil
+		}),
+		WithTimeout(50*time.Millisecond),
+	)
+	if !errors.Is(err, ErrJobTimeout) {
+		t.Errorf("expected %v; got %v", ErrJobTimeout, err)
+	}
+	if n := n.Load(); n != 2 {
+		t.Errorf("expected 2 completed jobs; got %d", n)
+	}
+
+	for range 20 {
+		err = Workers(2).RunContext(
+			context.Background(),
+			SliceContextJob([]int{1, 2}, func(ctx context.Context, _ int, _ int) error {
+				<-ctx.Done()
+				return ctx.Err()
+			}),
+			WithTimeout(5*time.Millisecond),
+		)
+		if !errors.Is(err, ErrJobTimeout) || errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected %v; got %v", ErrJobTimeout, err)
+		}
+	}
+
+	c := make(chan func(), 1)
+	c <- func() { <-hung }
+	close(c)
+	l := Workers(1).Listen(context.Background(), c, WithTimeout(10*time.Millisecond))
+	l.Wait()
+	if err := l.Err(); !errors.Is(err, ErrJobTimeout) {
+		t.Errorf("expected %v; got %v", ErrJobTimeout, err)
+	}
+}
+
+func TestWeighted(t *testing.T) {
+	var used, max atomic.Int64
+	job := SliceWeightedJob(
+		[]int64{1, 3, 2, 4, 1, 10},
+		func(w int64) int64 { return w },
+		func(_ context.Context, _ int, w int64) error {
+			w = min(w, 4)
+			n := used.Add(w)
+			defer used.Add(-w)
+			for m := max.Load(); n > m && !max.CompareAndSwap(m, n); m = max.Load() {
+			}
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		},
+	)
+	if err := Workers(4).RunWeighted(context.Background(), job); err != nil {
+		t.Fatal(err)
+	}
+	if n := max.Load(); n > 4 {
+		t.Errorf("expected at most 4 used weight; got %d", n)
+	}
+}